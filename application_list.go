@@ -0,0 +1,118 @@
+package qnap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Application represents an application with its containers, limits and compose definition
+type Application struct {
+	Name           string
+	Status         string
+	Containers     []ApplicationContainer
+	CPULimit       int32
+	MemLimit       int32
+	MemReservation int32
+	DefaultURL     DefaultURLModel
+	Yml            string
+	YmlHash        string // Hex encoded SHA-256 of Yml, to detect changed definitions
+}
+
+// ApplicationContainer represents a container of an application
+type ApplicationContainer struct {
+	ID     string
+	Name   string
+	Status string
+}
+
+// ListApplicationsOptions controls ListApplications
+type ListApplicationsOptions struct {
+	Names       []string // Only list these applications, all applications when empty
+	Concurrency int      // Maximum number of concurrent inspect requests, 4 when zero
+}
+
+// ListApplications returns every application with its details. The overview is fetched once
+// and the applications are inspected concurrently
+func (c *Client) ListApplications(ctx context.Context, options ListApplicationsOptions, authToken *string) ([]Application, error) {
+	overview, err := c.GetContainerStationOverview()
+	if err != nil {
+		return nil, err
+	}
+
+	containerStatus := make(map[string]string, len(overview.Data.Container))
+	for _, container := range overview.Data.Container {
+		containerStatus[container.ID] = container.Status
+	}
+
+	var applications []Application
+	for _, app := range overview.Data.App {
+		if len(options.Names) == 0 || slices.Contains(options.Names, app.Name) {
+			applications = append(applications, Application{Name: app.Name, Status: app.Status})
+		}
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	semaphore := make(chan struct{}, concurrency)
+
+	for i := range applications {
+		wg.Add(1)
+		go func(application *Application) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+
+			applicationResp, err := c.inspectApplication(ctx, application.Name, authToken)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("inspect application %s: %w", application.Name, err)
+					cancel()
+				})
+				return
+			}
+
+			data := applicationResp.Data
+			application.CPULimit = data.CPULimit
+			application.MemLimit = data.MemLimit
+			application.MemReservation = data.MemReservation
+			application.DefaultURL = data.DefaultURL
+			application.Yml = data.Yml
+			hash := sha256.Sum256([]byte(data.Yml))
+			application.YmlHash = hex.EncodeToString(hash[:])
+			for _, container := range data.Containers {
+				application.Containers = append(application.Containers, ApplicationContainer{
+					ID:     container.ID,
+					Name:   container.Name,
+					Status: containerStatus[container.ID],
+				})
+			}
+		}(&applications[i])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
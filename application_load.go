@@ -0,0 +1,163 @@
+package qnap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mohamed-mfarag/qnap-client-lib/compose"
+	"gopkg.in/yaml.v3"
+)
+
+// composeFileNames are the compose file names LoadApplication looks for, in order of preference
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+var projectNameInvalidChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// qnapExtension represents the x-qnap block of a compose file
+type qnapExtension struct {
+	DefaultURL struct {
+		Service string `yaml:"service"`
+		Port    int32  `yaml:"port"`
+	} `yaml:"default_url"`
+	CPULimit       *float64 `yaml:"cpu_limit"`       // Number of CPUs, overrides the deploy limits
+	MemLimit       string   `yaml:"mem_limit"`       // Memory size such as 1g, overrides the deploy limits
+	MemReservation string   `yaml:"mem_reservation"` // Memory size such as 512m, overrides the deploy reservations
+}
+
+// LoadApplication reads the compose file and .env file of a directory into a NewAppReqModel.
+// Variables are interpolated from .env and the process environment, which takes precedence.
+// The name is the compose project name, the directory name otherwise. DefaultURL comes from the x-qnap block,
+// CPULimit (hundredths of a CPU) and MemLimit/MemReservation (MiB) are the sums of the service deploy resources
+// unless x-qnap sets cpu_limit, mem_limit or mem_reservation
+func LoadApplication(dir string) (NewAppReqModel, error) {
+	composeFile := ""
+	for _, name := range composeFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			composeFile = filepath.Join(dir, name)
+			break
+		}
+	}
+	if composeFile == "" {
+		return NewAppReqModel{}, errors.New("no compose file found in " + dir)
+	}
+
+	raw, err := os.ReadFile(composeFile)
+	if err != nil {
+		return NewAppReqModel{}, err
+	}
+
+	env := map[string]string{}
+	envFile := filepath.Join(dir, ".env")
+	if _, err := os.Stat(envFile); err == nil {
+		env, err = compose.ReadEnvFile(envFile)
+		if err != nil {
+			return NewAppReqModel{}, err
+		}
+	}
+	lookup := func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		value, ok := env[name]
+		return value, ok
+	}
+
+	yml, err := compose.Interpolate(raw, lookup)
+	if err != nil {
+		return NewAppReqModel{}, fmt.Errorf("%s: %w", composeFile, err)
+	}
+
+	project, err := compose.Parse(yml)
+	if err != nil {
+		return NewAppReqModel{}, fmt.Errorf("%s: %w", composeFile, err)
+	}
+
+	application := NewAppReqModel{
+		Name: project.Name,
+		Yml:  string(yml),
+	}
+	if application.Name == "" {
+		application.Name, _ = lookup("COMPOSE_PROJECT_NAME")
+	}
+	if application.Name == "" {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return NewAppReqModel{}, err
+		}
+		application.Name = projectNameInvalidChars.ReplaceAllString(strings.ToLower(filepath.Base(absDir)), "")
+	}
+
+	var cpus float64
+	var memLimit, memReservation int64
+	for _, service := range project.Services {
+		if service == nil || service.Deploy == nil {
+			continue
+		}
+		if limits := service.Deploy.Resources.Limits; limits != nil {
+			if limits.CPUs != "" {
+				serviceCPUs, err := strconv.ParseFloat(limits.CPUs, 64)
+				if err != nil {
+					return NewAppReqModel{}, fmt.Errorf("%s: cpus %q: %w", composeFile, limits.CPUs, err)
+				}
+				cpus += serviceCPUs
+			}
+			if limits.Memory != "" {
+				memory, err := compose.ParseMemory(limits.Memory)
+				if err != nil {
+					return NewAppReqModel{}, fmt.Errorf("%s: %w", composeFile, err)
+				}
+				memLimit += memory
+			}
+		}
+		if reservations := service.Deploy.Resources.Reservations; reservations != nil && reservations.Memory != "" {
+			memory, err := compose.ParseMemory(reservations.Memory)
+			if err != nil {
+				return NewAppReqModel{}, fmt.Errorf("%s: %w", composeFile, err)
+			}
+			memReservation += memory
+		}
+	}
+
+	if extension, ok := project.Extras["x-qnap"]; ok {
+		var qnap qnapExtension
+		node, err := yaml.Marshal(extension)
+		if err == nil {
+			err = yaml.Unmarshal(node, &qnap)
+		}
+		if err != nil {
+			return NewAppReqModel{}, fmt.Errorf("%s: x-qnap: %w", composeFile, err)
+		}
+
+		application.DefaultURL = NewAppReqDefaultURLModel{
+			Port:    qnap.DefaultURL.Port,
+			Service: qnap.DefaultURL.Service,
+		}
+		if qnap.CPULimit != nil {
+			cpus = *qnap.CPULimit
+		}
+		if qnap.MemLimit != "" {
+			memLimit, err = compose.ParseMemory(qnap.MemLimit)
+			if err != nil {
+				return NewAppReqModel{}, fmt.Errorf("%s: x-qnap: %w", composeFile, err)
+			}
+		}
+		if qnap.MemReservation != "" {
+			memReservation, err = compose.ParseMemory(qnap.MemReservation)
+			if err != nil {
+				return NewAppReqModel{}, fmt.Errorf("%s: x-qnap: %w", composeFile, err)
+			}
+		}
+	}
+
+	application.CPULimit = cpusToLimit(cpus)
+	application.MemLimit = int32(math.Ceil(float64(memLimit) / (1 << 20)))
+	application.MemReservation = int32(math.Ceil(float64(memReservation) / (1 << 20)))
+
+	return application, nil
+}
@@ -0,0 +1,105 @@
+package qnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mohamed-mfarag/qnap-client-lib/compose"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadApplication(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My-Shop")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"docker-compose.yml": `# ${UNDOCUMENTED:?} only matters in a comment
+services:
+  web:
+    image: nginx:${TAG:-latest}
+    ports:
+      - ${WEB_PORT}:80
+    environment:
+      OTHER: ${OTHER}
+      DB_PASS: ${DB_PASS}
+      LITERAL: $$HOME
+      PASSWORD: ${PASSWORD}
+    deploy:
+      resources:
+        limits:
+          cpus: ${WEB_CPUS}
+          memory: 512M
+  db:
+    image: postgres:16
+    deploy:
+      resources:
+        limits: {cpus: "0.25", memory: 256M}
+        reservations: {memory: 128M}
+x-qnap:
+  default_url: {service: web, port: 8080}
+`,
+		".env": "WEB_PORT=8080\nOTHER=a: b\nDB_PASS=\"s3cret #1\"\nWEB_CPUS=0.5\nTAG=from-env-file\nPASSWORD='pa$word'\n",
+	})
+	t.Setenv("TAG", "1.27")
+
+	application, err := LoadApplication(dir)
+	if err != nil {
+		t.Fatalf("LoadApplication: %v", err)
+	}
+
+	if application.Name != "my-shop" {
+		t.Errorf("name %q, want my-shop", application.Name)
+	}
+	if application.CPULimit != 75 || application.MemLimit != 768 || application.MemReservation != 128 {
+		t.Errorf("limits %d, %d, %d, want 75, 768, 128", application.CPULimit, application.MemLimit, application.MemReservation)
+	}
+	if application.DefaultURL != (NewAppReqDefaultURLModel{Service: "web", Port: 8080}) {
+		t.Errorf("default URL %+v", application.DefaultURL)
+	}
+
+	project, err := compose.Parse([]byte(application.Yml))
+	if err != nil {
+		t.Fatalf("interpolated YAML does not parse: %v\n%s", err, application.Yml)
+	}
+	if err := project.Validate(); err != nil {
+		t.Errorf("interpolated YAML does not validate: %v", err)
+	}
+
+	web := project.Services["web"]
+	if web.Image != "nginx:1.27" {
+		t.Errorf("image %q, the environment must take precedence over .env", web.Image)
+	}
+	if len(web.Ports) != 1 || web.Ports[0] != (compose.ServicePort{Published: "8080", Target: "80"}) {
+		t.Errorf("ports %+v", web.Ports)
+	}
+	// Dollars stay escaped, Container Station interpolates the file again
+	for key, want := range map[string]string{"OTHER": "a: b", "DB_PASS": "s3cret #1", "LITERAL": "$$HOME", "PASSWORD": "pa$$word"} {
+		if value := web.Environment[key]; value == nil || *value != want {
+			t.Errorf("environment %s = %v, want %q", key, value, want)
+		}
+	}
+}
+
+func TestLoadApplicationErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadApplication(dir); err == nil || !strings.Contains(err.Error(), "no compose file found") {
+		t.Errorf("empty directory error %v", err)
+	}
+
+	writeFiles(t, dir, map[string]string{"compose.yaml": "services:\n  web:\n    image: ${IMAGE:?set IMAGE in .env}\n"})
+	_, err := LoadApplication(dir)
+	if err == nil || !strings.Contains(err.Error(), "line 3: required variable IMAGE set IMAGE in .env") {
+		t.Errorf("required variable error %v", err)
+	}
+}
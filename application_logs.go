@@ -0,0 +1,255 @@
+package qnap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// applicationLogsFlushInterval is how long a followed log line waits for lines of quiet containers
+// that may be older, before it is written anyway
+var applicationLogsFlushInterval = 250 * time.Millisecond
+
+// applicationLogsBuffer is the number of lines read ahead from each container log stream
+const applicationLogsBuffer = 64
+
+// replicaSuffix matches the replica number compose appends to container names
+var replicaSuffix = regexp.MustCompile(`[-_][0-9]+$`)
+
+// applicationLogLine is one log line of an application container
+type applicationLogLine struct {
+	time    time.Time
+	service string
+	text    string
+}
+
+// applicationLogSource is the log stream of one application container, read line by line.
+// err is set before lines is closed
+type applicationLogSource struct {
+	service string
+	stream  io.ReadCloser
+	lines   chan applicationLogLine
+	err     error
+}
+
+// ApplicationLogs merges the logs of all containers of an application into one stream ordered by time,
+// each line prefixed with its service name like docker compose logs. The caller must close the stream.
+// The container logs are already ordered, so they are merged line by line without collecting them.
+// In follow mode a line waits briefly for older lines of quiet containers before it is written
+func (c *Client) ApplicationLogs(ctx context.Context, applicationName string, options LogOptions, authToken *string) (io.ReadCloser, error) {
+	overview, err := c.GetContainerStationOverview()
+	if err != nil {
+		return nil, err
+	}
+	containerTypes := make(map[string]string, len(overview.Data.Container))
+	for _, container := range overview.Data.Container {
+		containerTypes[container.ID] = container.Type
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	application, err := c.inspectApplication(ctx, applicationName, authToken)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Timestamps are needed to order the lines, they are removed again unless requested
+	containerOptions := options
+	containerOptions.Timestamps = true
+
+	var sources []*applicationLogSource
+	closeSources := func() {
+		cancel()
+		for _, source := range sources {
+			source.stream.Close()
+		}
+	}
+	width := 0
+	for _, container := range application.Data.Containers {
+		containerType, found := containerTypes[container.ID]
+		if !found {
+			closeSources()
+			return nil, errors.New("container " + container.Name + " of application " + applicationName + " is not in the overview")
+		}
+
+		stream, err := c.ContainerLogs(ctx, container.ID, containerType, containerOptions, authToken)
+		if err != nil {
+			closeSources()
+			return nil, fmt.Errorf("logs of container %s: %w", container.Name, err)
+		}
+		service := serviceName(applicationName, container.Name)
+		if len(service) > width {
+			width = len(service)
+		}
+		sources = append(sources, &applicationLogSource{
+			service: service,
+			stream:  stream,
+			lines:   make(chan applicationLogLine, applicationLogsBuffer),
+		})
+	}
+
+	// ready is signalled whenever a source has a new line or ended
+	ready := make(chan struct{}, 1)
+	for _, source := range sources {
+		go source.read(ctx, options.Timestamps, ready)
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		defer cancel()
+		pipeWriter.CloseWithError(mergeApplicationLogs(ctx, sources, ready, options.Follow, func(line applicationLogLine) error {
+			_, err := fmt.Fprintf(pipeWriter, "%-*s | %s\n", width, line.service, line.text)
+			return err
+		}))
+	}()
+
+	return &applicationLogStream{PipeReader: pipeReader, cancel: cancel}, nil
+}
+
+// read sends the lines of the stream, with their timestamp removed unless timestamps is set.
+// A line without a timestamp is ordered as the line before it
+func (source *applicationLogSource) read(ctx context.Context, timestamps bool, ready chan<- struct{}) {
+	defer func() {
+		source.stream.Close()
+		close(source.lines)
+		notify(ready)
+	}()
+
+	var last time.Time
+	scanner := bufio.NewScanner(source.stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := applicationLogLine{service: source.service, text: scanner.Text(), time: last}
+		if timestamp, text, ok := strings.Cut(line.text, " "); ok {
+			if parsed, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+				line.time = parsed
+				last = parsed
+				if !timestamps {
+					line.text = text
+				}
+			}
+		}
+		select {
+		case source.lines <- line:
+			notify(ready)
+		case <-ctx.Done():
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		source.err = fmt.Errorf("logs of service %s: %w", source.service, err)
+	}
+}
+
+// notify signals ready without blocking, a pending signal already covers the new event
+func notify(ready chan<- struct{}) {
+	select {
+	case ready <- struct{}{}:
+	default:
+	}
+}
+
+// mergeApplicationLogs writes the lines of all sources in time order. A line is written once every
+// source still open has a line waiting, so no older line can follow it. In follow mode a line is also
+// written when it waited applicationLogsFlushInterval, a quiet container does not hold back the others
+func mergeApplicationLogs(ctx context.Context, sources []*applicationLogSource, ready <-chan struct{}, follow bool, write func(applicationLogLine) error) error {
+	heads := make([]*applicationLogLine, len(sources))
+	arrived := make([]time.Time, len(sources))
+	finished := make([]bool, len(sources))
+
+	for {
+		for i, source := range sources {
+			if heads[i] != nil || finished[i] {
+				continue
+			}
+			select {
+			case line, ok := <-source.lines:
+				if !ok {
+					if source.err != nil {
+						return source.err
+					}
+					finished[i] = true
+					continue
+				}
+				heads[i] = &line
+				arrived[i] = time.Now()
+			default:
+			}
+		}
+
+		next := -1
+		waiting := false
+		for i := range sources {
+			if heads[i] == nil {
+				waiting = waiting || !finished[i]
+				continue
+			}
+			if next < 0 || heads[i].time.Before(heads[next].time) {
+				next = i
+			}
+		}
+		if next < 0 && !waiting {
+			return nil
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if next >= 0 {
+			wait := applicationLogsFlushInterval - time.Since(arrived[next])
+			if !waiting || (follow && wait <= 0) {
+				err := write(*heads[next])
+				if err != nil {
+					return err
+				}
+				heads[next] = nil
+				continue
+			}
+			if follow {
+				timer = time.NewTimer(wait)
+				timeout = timer.C
+			}
+		}
+
+		select {
+		case <-ready:
+		case <-timeout:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// applicationLogStream stops reading the container logs when it is closed
+type applicationLogStream struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+// Close stops the container log streams
+func (s *applicationLogStream) Close() error {
+	s.cancel()
+	return s.PipeReader.Close()
+}
+
+// serviceName derives the compose service from a container name such as app-web-1 or app_web_1
+func serviceName(applicationName string, containerName string) string {
+	name := strings.TrimPrefix(containerName, "/")
+	for _, separator := range []string{"-", "_"} {
+		if strings.HasPrefix(name, applicationName+separator) {
+			name = strings.TrimPrefix(name, applicationName+separator)
+			break
+		}
+	}
+	return replicaSuffix.ReplaceAllString(name, "")
+}
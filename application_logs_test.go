@@ -0,0 +1,157 @@
+package qnap
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLogStation(t *testing.T, web string, db string) *fakeStation {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "c1", Name: "shop-web-1", Type: "docker", Status: "running", Logs: web})
+	// The type of each container comes from the overview, the fake rejects any other
+	station.add(&fakeContainer{ID: "c2", Name: "/shop_db_1", Type: "lxd", Status: "running", Logs: db})
+	station.addApp("shop", "c1", "c2")
+	return station
+}
+
+func TestApplicationLogs(t *testing.T) {
+	station := newLogStation(t,
+		"2024-05-01T10:00:00.1Z web one\n2024-05-01T10:00:02Z web two\ncontinued\n2024-05-01T10:00:04Z web three\n",
+		"2024-05-01T10:00:01Z db one\n2024-05-01T10:00:03Z db two\n",
+	)
+	client := station.client()
+
+	tests := []struct {
+		name    string
+		options LogOptions
+		want    string
+	}{
+		{
+			name: "merged by time",
+			want: "web | web one\ndb  | db one\nweb | web two\nweb | continued\ndb  | db two\nweb | web three\n",
+		},
+		{
+			name:    "with timestamps",
+			options: LogOptions{Timestamps: true},
+			want: "web | 2024-05-01T10:00:00.1Z web one\ndb  | 2024-05-01T10:00:01Z db one\n" +
+				"web | 2024-05-01T10:00:02Z web two\nweb | continued\ndb  | 2024-05-01T10:00:03Z db two\n" +
+				"web | 2024-05-01T10:00:04Z web three\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stream, err := client.ApplicationLogs(context.Background(), "shop", test.options, &client.Token)
+			if err != nil {
+				t.Fatalf("ApplicationLogs: %v", err)
+			}
+			defer stream.Close()
+
+			got, err := io.ReadAll(stream)
+			if err != nil {
+				t.Fatalf("read logs: %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("logs:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplicationLogsStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      *fakeContainer
+		problem string
+	}{
+		{
+			name:    "broken stream",
+			db:      &fakeContainer{Logs: "2024-05-01T10:00:01Z db one\n", Broken: true},
+			problem: "logs of service db: unexpected EOF",
+		},
+		{
+			name:    "line too long",
+			db:      &fakeContainer{Logs: "2024-05-01T10:00:01Z " + strings.Repeat("x", 2<<20) + "\n"},
+			problem: "logs of service db: " + bufio.ErrTooLong.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			station := newLogStation(t, "2024-05-01T10:00:00Z web one\n", test.db.Logs)
+			station.find("c2").Broken = test.db.Broken
+			client := station.client()
+
+			stream, err := client.ApplicationLogs(context.Background(), "shop", LogOptions{}, &client.Token)
+			if err != nil {
+				t.Fatalf("ApplicationLogs: %v", err)
+			}
+			defer stream.Close()
+
+			_, err = io.ReadAll(stream)
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("read error %v, want %q", err, test.problem)
+			}
+		})
+	}
+}
+
+func TestApplicationLogsFollow(t *testing.T) {
+	defer func(interval time.Duration) { applicationLogsFlushInterval = interval }(applicationLogsFlushInterval)
+	applicationLogsFlushInterval = 20 * time.Millisecond
+
+	// db stays quiet, web lines are written after waiting for it
+	station := newLogStation(t, "2024-05-01T10:00:00Z web one\n2024-05-01T10:00:02Z web two\n", "")
+	client := station.client()
+
+	stream, err := client.ApplicationLogs(context.Background(), "shop", LogOptions{Follow: true}, &client.Token)
+	if err != nil {
+		t.Fatalf("ApplicationLogs: %v", err)
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	for _, want := range []string{"web | web one", "web | web two"} {
+		select {
+		case line := <-lines:
+			if line != want {
+				t.Errorf("line %q, want %q", line, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no line %q while db is quiet", want)
+		}
+	}
+
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	select {
+	case line, ok := <-lines:
+		if ok {
+			t.Errorf("line %q after Close", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not closed")
+	}
+}
+
+func TestApplicationLogsUnknownApplication(t *testing.T) {
+	station := newLogStation(t, "", "")
+	client := station.client()
+
+	_, err := client.ApplicationLogs(context.Background(), "missing", LogOptions{}, &client.Token)
+	if err == nil {
+		t.Errorf("ApplicationLogs of a missing application: %v", err)
+	}
+}
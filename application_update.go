@@ -0,0 +1,90 @@
+package qnap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mohamed-mfarag/qnap-client-lib/compose"
+)
+
+// ServiceChanges lists the compose services that differ between two application definitions
+type ServiceChanges struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+	Err      error // Why the definitions could not be compared, the lists are empty then
+}
+
+// UpdateApplication replaces the compose definition and limits of an existing application by recreating it,
+// and reports which services changed compared with the definition it had before.
+// The report is best effort, when the previous definition can't be read or parsed the application is
+// still recreated and the reason is in the Err field of the changes
+func (c *Client) UpdateApplication(applicationName string, application NewAppReqModel, authToken *string) (*AppRespModel, ServiceChanges, error) {
+	overview, err := c.GetContainerStationOverview()
+	if err != nil {
+		return nil, ServiceChanges{}, err
+	}
+
+	exists := false
+	for _, app := range overview.Data.App {
+		if app.Name == applicationName {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		return nil, ServiceChanges{}, errors.New("can't update application " + applicationName + " as it does not exist")
+	}
+
+	var changes ServiceChanges
+	previous, err := c.InspectApplication(applicationName, authToken)
+	if err == nil {
+		changes, err = diffComposeServices(previous.Data.Yml, application.Yml)
+	}
+	if err != nil {
+		changes = ServiceChanges{Err: fmt.Errorf("compare services: %w", err)}
+	}
+
+	application.Name = applicationName
+	application.Operation = "recreate"
+
+	updated, err := c.CreateApplication(application, authToken)
+	if err != nil {
+		return nil, changes, err
+	}
+	return updated, changes, nil
+}
+
+// diffComposeServices compares the services of two compose files
+func diffComposeServices(previousYml string, yml string) (ServiceChanges, error) {
+	previous, err := compose.Parse([]byte(previousYml))
+	if err != nil {
+		return ServiceChanges{}, fmt.Errorf("previous definition: %w", err)
+	}
+	current, err := compose.Parse([]byte(yml))
+	if err != nil {
+		return ServiceChanges{}, fmt.Errorf("new definition: %w", err)
+	}
+
+	var changes ServiceChanges
+	for name, service := range current.Services {
+		previousService, existed := previous.Services[name]
+		if !existed {
+			changes.Added = append(changes.Added, name)
+		} else if !reflect.DeepEqual(previousService, service) {
+			changes.Modified = append(changes.Modified, name)
+		}
+	}
+	for name := range previous.Services {
+		if _, exists := current.Services[name]; !exists {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Modified)
+	return changes, nil
+}
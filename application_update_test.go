@@ -0,0 +1,141 @@
+package qnap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffComposeServices(t *testing.T) {
+	previous := `services:
+  web:
+    image: nginx:1.25
+    ports: ["8080:80"]
+  db:
+    image: postgres:16
+  cache:
+    image: redis:7
+    x-notes: first
+  worker:
+    image: worker:1
+`
+
+	tests := []struct {
+		name string
+		yml  string
+		want ServiceChanges
+	}{
+		{
+			name: "unchanged",
+			yml:  previous,
+		},
+		{
+			name: "added, removed and modified",
+			yml: `services:
+  web:
+    image: nginx:1.27
+    ports: ["8080:80"]
+  db:
+    image: postgres:16
+  cache:
+    image: redis:7
+    x-notes: first
+  worker:
+    image: worker:1
+  proxy:
+    image: traefik:3
+`,
+			want: ServiceChanges{Added: []string{"proxy"}, Modified: []string{"web"}},
+		},
+		{
+			name: "removed and changed extras key",
+			yml: `services:
+  web:
+    image: nginx:1.25
+    ports: ["8080:80"]
+  cache:
+    image: redis:7
+    x-notes: second
+  worker:
+    image: worker:1
+    build: ./worker
+`,
+			want: ServiceChanges{Removed: []string{"db"}, Modified: []string{"cache", "worker"}},
+		},
+		{
+			name: "reordered keys and services are not changes",
+			yml: `services:
+  worker: {image: worker:1}
+  cache: {x-notes: first, image: redis:7}
+  db: {image: postgres:16}
+  web: {ports: ["8080:80"], image: nginx:1.25}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := diffComposeServices(previous, test.yml)
+			if err != nil {
+				t.Fatalf("diffComposeServices: %v", err)
+			}
+			if !reflect.DeepEqual(changes, test.want) {
+				t.Errorf("changes %+v, want %+v", changes, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateApplication(t *testing.T) {
+	yml := "services:\n  web:\n    image: nginx:1.27\n"
+
+	tests := []struct {
+		name        string
+		previousYml string
+		want        ServiceChanges
+		problem     string // Expected substring of changes.Err
+	}{
+		{
+			name:        "services compared",
+			previousYml: "services:\n  web:\n    image: nginx:1.25\n  db:\n    image: postgres:16\n",
+			want:        ServiceChanges{Removed: []string{"db"}, Modified: []string{"web"}},
+		},
+		{
+			name:    "empty previous definition",
+			problem: "compare services: previous definition: compose file is empty",
+		},
+		{
+			name:        "previous definition does not parse",
+			previousYml: "services: [broken\n",
+			problem:     "compare services: previous definition: yaml",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			station := newFakeStation(t)
+			station.addApp("shop")
+			station.appYml["shop"] = test.previousYml
+			client := station.client()
+
+			updated, changes, err := client.UpdateApplication("shop", NewAppReqModel{Yml: yml}, &client.Token)
+			if err != nil {
+				t.Fatalf("UpdateApplication: %v", err)
+			}
+			// The application is recreated whether or not the services could be compared
+			if updated.Data.Yml != yml {
+				t.Errorf("application yml %q, want %q", updated.Data.Yml, yml)
+			}
+
+			if test.problem != "" {
+				if changes.Err == nil || !strings.Contains(changes.Err.Error(), test.problem) {
+					t.Errorf("changes error %v, want %q", changes.Err, test.problem)
+				}
+				changes.Err = nil
+			}
+			if !reflect.DeepEqual(changes, test.want) {
+				t.Errorf("changes %+v, want %+v", changes, test.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package qnap
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/mohamed-mfarag/qnap-client-lib/compose"
 )
 
 type RemoveApplication struct {
@@ -22,6 +25,18 @@ func (c *Client) CreateApplication(application NewAppReqModel, authToken *string
 	applicationName := application.Name
 	applicationOperation := application.Operation
 
+	// Catch YAML and schema mistakes before they end up as a failed task
+	if !application.SkipValidation {
+		project, err := compose.Parse([]byte(application.Yml))
+		if err != nil {
+			return nil, fmt.Errorf("invalid application yml: %w", err)
+		}
+		err = project.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid application yml: %w", err)
+		}
+	}
+
 	rb, err := json.Marshal(application)
 	if err != nil {
 		return nil, err
@@ -98,33 +113,43 @@ func (c *Client) CreateApplication(application NewAppReqModel, authToken *string
 
 // InspectApplication - Returns specific container specifications (not inspect function)
 func (c *Client) InspectApplication(applicationName string, authToken *string) (*AppRespModel, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/container-station/api/v3/apps/%s/inspect", c.HostURL, applicationName), nil)
+	applicationResp, err := c.inspectApplication(context.Background(), applicationName, authToken)
 	if err != nil {
 		return nil, err
 	}
 
-	body, _, err := c.doRequest(req, authToken)
+	// Do request with application name to inspect
+	applicationsAfter, err := c.GetContainerStationOverview()
 	if err != nil {
 		return nil, err
 	}
 
-	var applicationResp AppRespModel
-	err = json.Unmarshal(body, &applicationResp)
+	// Check if application is created
+	for _, applicationAfter := range applicationsAfter.Data.App {
+		if applicationAfter.Name == applicationName {
+			applicationResp.Data.Status = applicationAfter.Status
+		}
+	}
+
+	return applicationResp, nil
+}
+
+// inspectApplication returns the application specifications without the status from the overview
+func (c *Client) inspectApplication(ctx context.Context, applicationName string, authToken *string) (*AppRespModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/container-station/api/v3/apps/%s/inspect", c.HostURL, applicationName), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Do request with application name to inspect
-	applicationsAfter, err := c.GetContainerStationOverview()
+	body, _, err := c.doRequest(req, authToken)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if application is created
-	for _, applicationAfter := range applicationsAfter.Data.App {
-		if applicationAfter.Name == applicationName {
-			applicationResp.Data.Status = applicationAfter.Status
-		}
+	var applicationResp AppRespModel
+	err = json.Unmarshal(body, &applicationResp)
+	if err != nil {
+		return nil, err
 	}
 
 	return &applicationResp, nil
@@ -68,9 +68,7 @@ func (c *Client) doRequest(req *http.Request, authToken *string) ([]byte, string
 
 	if authToken != nil {
 		token = *authToken
-		req.Header.Set("Authorization", "Bearer "+strings.Split(token, "=")[1])
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Cookie", token)
+		setAuthHeaders(req, token)
 	}
 
 	res, err := c.HTTPClient.Do(req) // Send the HTTP request
@@ -92,3 +90,36 @@ func (c *Client) doRequest(req *http.Request, authToken *string) ([]byte, string
 
 	return body, token, err
 }
+
+// doStreamRequest sends an HTTP request and returns the unread response body, which the caller must close.
+// The client timeout is not applied so the stream only ends with the request context or the server
+func (c *Client) doStreamRequest(req *http.Request, authToken *string) (io.ReadCloser, error) {
+	if authToken != nil {
+		setAuthHeaders(req, *authToken)
+	}
+
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+
+	res, err := httpClient.Do(req) // Send the HTTP request
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("status: %d, body: %s", res.StatusCode, body)
+	}
+
+	return res.Body, nil
+}
+
+// setAuthHeaders adds the session token to the request, keeping a content type already set by the caller
+func setAuthHeaders(req *http.Request, token string) {
+	req.Header.Set("Authorization", "Bearer "+strings.Split(token, "=")[1])
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Cookie", token)
+}
@@ -0,0 +1,461 @@
+// Package compose parses, validates and marshals the compose files used by Container Station applications.
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project represents a compose file
+type Project struct {
+	Version  string              `yaml:"version,omitempty"`
+	Name     string              `yaml:"name,omitempty"`
+	Services map[string]*Service `yaml:"services"`
+	Networks map[string]*Network `yaml:"networks,omitempty"`
+	Volumes  map[string]*Volume  `yaml:"volumes,omitempty"`
+	Extras   map[string]any      `yaml:",inline"` // Other top level keys such as x-* extensions
+}
+
+// Service represents a service of a compose file
+type Service struct {
+	Image         string          `yaml:"image,omitempty"`
+	ContainerName string          `yaml:"container_name,omitempty"`
+	Command       ShellCommand    `yaml:"command,omitempty"`
+	Entrypoint    ShellCommand    `yaml:"entrypoint,omitempty"`
+	Environment   Mapping         `yaml:"environment,omitempty"`
+	Labels        Mapping         `yaml:"labels,omitempty"`
+	Ports         []ServicePort   `yaml:"ports,omitempty"`
+	Volumes       []ServiceVolume `yaml:"volumes,omitempty"`
+	Networks      ServiceNetworks `yaml:"networks,omitempty"`
+	DependsOn     DependsOn       `yaml:"depends_on,omitempty"`
+	Deploy        *Deploy         `yaml:"deploy,omitempty"`
+	Restart       string          `yaml:"restart,omitempty"`
+	Extras        map[string]any  `yaml:",inline"` // Other service keys such as build or healthcheck
+}
+
+// Network represents a top level network of a compose file
+type Network struct {
+	Name     string         `yaml:"name,omitempty"`
+	Driver   string         `yaml:"driver,omitempty"`
+	External any            `yaml:"external,omitempty"`
+	Extras   map[string]any `yaml:",inline"`
+}
+
+// Volume represents a top level volume of a compose file
+type Volume struct {
+	Name     string         `yaml:"name,omitempty"`
+	Driver   string         `yaml:"driver,omitempty"`
+	External any            `yaml:"external,omitempty"`
+	Extras   map[string]any `yaml:",inline"`
+}
+
+// Deploy represents the deploy section of a service
+type Deploy struct {
+	Resources Resources      `yaml:"resources,omitempty"`
+	Extras    map[string]any `yaml:",inline"`
+}
+
+// Resources represents the resource limits and reservations of a service
+type Resources struct {
+	Limits       *Resource `yaml:"limits,omitempty"`
+	Reservations *Resource `yaml:"reservations,omitempty"`
+}
+
+// Resource represents a CPU and memory amount, for example cpus "0.5" and memory "512M"
+type Resource struct {
+	CPUs   string         `yaml:"cpus,omitempty"`
+	Memory string         `yaml:"memory,omitempty"`
+	Extras map[string]any `yaml:",inline"`
+}
+
+// ShellCommand is a command given either as a string or as a list of arguments
+type ShellCommand []string
+
+// UnmarshalYAML splits a string command like a POSIX shell and keeps a list as it is
+func (command *ShellCommand) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			*command = nil
+			return nil
+		}
+		args, err := splitShellWords(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		*command = args
+		return nil
+	}
+
+	var args []string
+	err := node.Decode(&args)
+	if err != nil {
+		return err
+	}
+	*command = args
+	return nil
+}
+
+// splitShellWords splits a command line on whitespace, honouring single quotes, double quotes and backslashes
+func splitShellWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape in command " + line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Mapping is a set of key value pairs given either as a map or as a list of KEY=VALUE strings.
+// A nil value stands for a key without value, which compose resolves from the environment
+type Mapping map[string]*string
+
+// UnmarshalYAML reads the map and the list form
+func (mapping *Mapping) UnmarshalYAML(node *yaml.Node) error {
+	result := Mapping{}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var entries []string
+		err := node.Decode(&entries)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			key, value, ok := strings.Cut(entry, "=")
+			if ok {
+				result[key] = &value
+			} else {
+				result[key] = nil
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: value of %s must be a scalar", value.Line, key.Value)
+			}
+			if value.Tag == "!!null" {
+				result[key.Value] = nil
+			} else {
+				scalar := value.Value
+				result[key.Value] = &scalar
+			}
+		}
+	default:
+		return fmt.Errorf("line %d: expected a map or a list", node.Line)
+	}
+
+	*mapping = result
+	return nil
+}
+
+// MarshalYAML writes the map form
+func (mapping Mapping) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range sortedKeys(mapping) {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		if mapping[key] != nil {
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: *mapping[key]}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	return node, nil
+}
+
+// ServicePort represents a port of a service, Target and Published may be ranges such as "8000-8010"
+type ServicePort struct {
+	Target    string `yaml:"target"`
+	Published string `yaml:"published,omitempty"`
+	HostIP    string `yaml:"host_ip,omitempty"`
+	Protocol  string `yaml:"protocol,omitempty"`
+	Mode      string `yaml:"mode,omitempty"`
+}
+
+// UnmarshalYAML reads the short syntax [[host_ip:][published]:]target[/protocol] and the long syntax
+func (port *ServicePort) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		type plain ServicePort
+		return node.Decode((*plain)(port))
+	}
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: port must be a string or a map", node.Line)
+	}
+
+	*port = ServicePort{}
+	mapping, protocol, _ := strings.Cut(node.Value, "/")
+	port.Protocol = protocol
+
+	if strings.HasPrefix(mapping, "[") {
+		end := strings.Index(mapping, "]:")
+		if end < 0 {
+			return fmt.Errorf("line %d: invalid IPv6 host address in port %s", node.Line, node.Value)
+		}
+		port.HostIP = mapping[1:end]
+		mapping = mapping[end+2:]
+	}
+
+	parts := strings.Split(mapping, ":")
+	switch {
+	case len(parts) == 1:
+		port.Target = parts[0]
+	case len(parts) == 2:
+		port.Published, port.Target = parts[0], parts[1]
+	case len(parts) == 3 && port.HostIP == "":
+		port.HostIP, port.Published, port.Target = parts[0], parts[1], parts[2]
+	default:
+		return fmt.Errorf("line %d: invalid port %s", node.Line, node.Value)
+	}
+	return nil
+}
+
+// MarshalYAML writes the short syntax unless the port sets a mode
+func (port ServicePort) MarshalYAML() (any, error) {
+	if port.Mode != "" {
+		type plain ServicePort
+		return plain(port), nil
+	}
+
+	short := port.Target
+	if port.Published != "" || port.HostIP != "" {
+		short = port.Published + ":" + short
+		if port.HostIP != "" {
+			hostIP := port.HostIP
+			if strings.Contains(hostIP, ":") {
+				hostIP = "[" + hostIP + "]"
+			}
+			short = hostIP + ":" + short
+		}
+	}
+	if port.Protocol != "" {
+		short += "/" + port.Protocol
+	}
+	return short, nil
+}
+
+// ServiceVolume represents a mount of a service
+type ServiceVolume struct {
+	Type     string         `yaml:"type"`
+	Source   string         `yaml:"source,omitempty"`
+	Target   string         `yaml:"target"`
+	ReadOnly bool           `yaml:"read_only,omitempty"`
+	Extras   map[string]any `yaml:",inline"` // Options such as bind, volume or tmpfs
+}
+
+// UnmarshalYAML reads the short syntax [source:]target[:mode] and the long syntax
+func (volume *ServiceVolume) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		type plain ServiceVolume
+		return node.Decode((*plain)(volume))
+	}
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: volume must be a string or a map", node.Line)
+	}
+
+	*volume = ServiceVolume{Type: "volume"}
+	parts := strings.Split(node.Value, ":")
+	switch len(parts) {
+	case 1:
+		volume.Target = parts[0]
+		return nil
+	case 2:
+		volume.Source, volume.Target = parts[0], parts[1]
+	case 3:
+		volume.Source, volume.Target = parts[0], parts[1]
+		for _, option := range strings.Split(parts[2], ",") {
+			if option == "ro" {
+				volume.ReadOnly = true
+			}
+		}
+	default:
+		return fmt.Errorf("line %d: invalid volume %s", node.Line, node.Value)
+	}
+
+	if isPath(volume.Source) {
+		volume.Type = "bind"
+	}
+	return nil
+}
+
+// MarshalYAML writes the short syntax for plain bind mounts and volumes
+func (volume ServiceVolume) MarshalYAML() (any, error) {
+	if len(volume.Extras) > 0 || (volume.Type != "volume" && volume.Type != "bind") {
+		type plain ServiceVolume
+		return plain(volume), nil
+	}
+
+	short := volume.Target
+	if volume.Source != "" {
+		short = volume.Source + ":" + short
+	}
+	if volume.ReadOnly {
+		short += ":ro"
+	}
+	return short, nil
+}
+
+// isPath reports whether a volume source is a host path rather than a volume name
+func isPath(source string) bool {
+	return strings.HasPrefix(source, "/") || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "~")
+}
+
+// ServiceNetworks maps the networks a service joins to their settings, nil when the list form is used
+type ServiceNetworks map[string]*ServiceNetwork
+
+// ServiceNetwork represents the settings of a service on one network
+type ServiceNetwork struct {
+	Aliases     []string       `yaml:"aliases,omitempty"`
+	IPv4Address string         `yaml:"ipv4_address,omitempty"`
+	IPv6Address string         `yaml:"ipv6_address,omitempty"`
+	Extras      map[string]any `yaml:",inline"`
+}
+
+// UnmarshalYAML reads the list and the map form
+func (networks *ServiceNetworks) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var names []string
+		err := node.Decode(&names)
+		if err != nil {
+			return err
+		}
+		*networks = ServiceNetworks{}
+		for _, name := range names {
+			(*networks)[name] = nil
+		}
+		return nil
+	}
+
+	var settings map[string]*ServiceNetwork
+	err := node.Decode(&settings)
+	if err != nil {
+		return err
+	}
+	*networks = settings
+	return nil
+}
+
+// MarshalYAML writes the list form when no network has settings
+func (networks ServiceNetworks) MarshalYAML() (any, error) {
+	for _, settings := range networks {
+		if settings != nil {
+			return map[string]*ServiceNetwork(networks), nil
+		}
+	}
+	return sortedKeys(networks), nil
+}
+
+// DependsOn maps the services a service depends on to the condition they must meet
+type DependsOn map[string]ServiceDependency
+
+// ServiceDependency represents one dependency of a service
+type ServiceDependency struct {
+	Condition string         `yaml:"condition"`
+	Extras    map[string]any `yaml:",inline"`
+}
+
+// Dependency conditions of depends_on
+const (
+	ConditionServiceStarted               = "service_started"
+	ConditionServiceHealthy               = "service_healthy"
+	ConditionServiceCompletedSuccessfully = "service_completed_successfully"
+)
+
+// UnmarshalYAML reads the list and the map form, the list form waits for the services to start
+func (dependsOn *DependsOn) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var names []string
+		err := node.Decode(&names)
+		if err != nil {
+			return err
+		}
+		*dependsOn = DependsOn{}
+		for _, name := range names {
+			(*dependsOn)[name] = ServiceDependency{Condition: ConditionServiceStarted}
+		}
+		return nil
+	}
+
+	var dependencies map[string]ServiceDependency
+	err := node.Decode(&dependencies)
+	if err != nil {
+		return err
+	}
+	*dependsOn = dependencies
+	return nil
+}
+
+// MarshalYAML writes the list form when every dependency only waits for the service to start
+func (dependsOn DependsOn) MarshalYAML() (any, error) {
+	for _, dependency := range dependsOn {
+		if dependency.Condition != ConditionServiceStarted || len(dependency.Extras) > 0 {
+			return map[string]ServiceDependency(dependsOn), nil
+		}
+	}
+	return sortedKeys(dependsOn), nil
+}
+
+// Parse reads a compose file into a Project, it does not validate it
+func Parse(data []byte) (*Project, error) {
+	var project Project
+	err := yaml.Unmarshal(data, &project)
+	if err != nil {
+		return nil, err
+	}
+	if project.Services == nil && len(project.Extras) == 0 && project.Name == "" && project.Version == "" {
+		return nil, errors.New("compose file is empty")
+	}
+	return &project, nil
+}
+
+// Marshal writes the project back as a compose file
+func (project *Project) Marshal() ([]byte, error) {
+	return yaml.Marshal(project)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,317 @@
+package compose
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+// parseService parses a compose file with a single service named app
+func parseService(t *testing.T, service string) *Service {
+	t.Helper()
+
+	project, err := Parse([]byte("services:\n  app:\n" + service))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return project.Services["app"]
+}
+
+func TestMappingForms(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		want    Mapping
+	}{
+		{
+			name: "list form",
+			service: `    environment:
+      - TZ=UTC
+      - EMPTY=
+      - FROM_SHELL
+      - URL=postgres://db:5432/app?sslmode=disable
+`,
+			want: Mapping{"TZ": ptr("UTC"), "EMPTY": ptr(""), "FROM_SHELL": nil, "URL": ptr("postgres://db:5432/app?sslmode=disable")},
+		},
+		{
+			name: "map form",
+			service: `    environment:
+      TZ: UTC
+      PORT: 8080
+      DEBUG: true
+      FROM_SHELL:
+      QUOTED: ""
+`,
+			want: Mapping{"TZ": ptr("UTC"), "PORT": ptr("8080"), "DEBUG": ptr("true"), "FROM_SHELL": nil, "QUOTED": ptr("")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := parseService(t, "    image: app\n"+test.service)
+			if !reflect.DeepEqual(service.Environment, test.want) {
+				t.Errorf("environment %v, want %v", service.Environment, test.want)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("services:\n  app:\n    environment:\n      NESTED: {a: b}\n")); err == nil {
+		t.Error("non scalar environment value accepted")
+	}
+	if _, err := Parse([]byte("services:\n  app:\n    environment: TZ=UTC\n")); err == nil {
+		t.Error("scalar environment accepted")
+	}
+}
+
+func TestServiceNetworksForms(t *testing.T) {
+	list := parseService(t, "    networks: [frontend, backend]\n")
+	if want := (ServiceNetworks{"frontend": nil, "backend": nil}); !reflect.DeepEqual(list.Networks, want) {
+		t.Errorf("list form %v, want %v", list.Networks, want)
+	}
+
+	mapped := parseService(t, `    networks:
+      frontend:
+      backend:
+        aliases: [api]
+        ipv4_address: 10.0.0.5
+        priority: 100
+`)
+	want := ServiceNetworks{
+		"frontend": nil,
+		"backend":  {Aliases: []string{"api"}, IPv4Address: "10.0.0.5", Extras: map[string]any{"priority": 100}},
+	}
+	if !reflect.DeepEqual(mapped.Networks, want) {
+		t.Errorf("map form %+v, want %+v", mapped.Networks, want)
+	}
+}
+
+func TestDependsOnForms(t *testing.T) {
+	list := parseService(t, "    depends_on: [db, cache]\n")
+	want := DependsOn{"db": {Condition: ConditionServiceStarted}, "cache": {Condition: ConditionServiceStarted}}
+	if !reflect.DeepEqual(list.DependsOn, want) {
+		t.Errorf("list form %v, want %v", list.DependsOn, want)
+	}
+
+	mapped := parseService(t, `    depends_on:
+      db:
+        condition: service_healthy
+        restart: true
+      migrate:
+        condition: service_completed_successfully
+`)
+	want = DependsOn{
+		"db":      {Condition: ConditionServiceHealthy, Extras: map[string]any{"restart": true}},
+		"migrate": {Condition: ConditionServiceCompletedSuccessfully},
+	}
+	if !reflect.DeepEqual(mapped.DependsOn, want) {
+		t.Errorf("map form %v, want %v", mapped.DependsOn, want)
+	}
+}
+
+func TestServicePortSyntax(t *testing.T) {
+	tests := []struct {
+		port string
+		want ServicePort
+	}{
+		{port: `"80"`, want: ServicePort{Target: "80"}},
+		{port: `"8080:80"`, want: ServicePort{Published: "8080", Target: "80"}},
+		{port: `"127.0.0.1:8080:80/tcp"`, want: ServicePort{HostIP: "127.0.0.1", Published: "8080", Target: "80", Protocol: "tcp"}},
+		{port: `"127.0.0.1::80"`, want: ServicePort{HostIP: "127.0.0.1", Target: "80"}},
+		{port: `"[::1]:53:53/udp"`, want: ServicePort{HostIP: "::1", Published: "53", Target: "53", Protocol: "udp"}},
+		{port: `"9000-9002:9000-9002"`, want: ServicePort{Published: "9000-9002", Target: "9000-9002"}},
+		{port: `8080`, want: ServicePort{Target: "8080"}},
+		{
+			port: "{target: 80, published: \"8080\", host_ip: 0.0.0.0, protocol: tcp, mode: host}",
+			want: ServicePort{Target: "80", Published: "8080", HostIP: "0.0.0.0", Protocol: "tcp", Mode: "host"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.port, func(t *testing.T) {
+			service := parseService(t, "    ports:\n      - "+test.port+"\n")
+			if len(service.Ports) != 1 || service.Ports[0] != test.want {
+				t.Errorf("ports %+v, want %+v", service.Ports, test.want)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("services:\n  app:\n    ports: [\"[::1:80\"]\n")); err == nil {
+		t.Error("unterminated IPv6 address accepted")
+	}
+	if _, err := Parse([]byte("services:\n  app:\n    ports: [\"1:2:3:4\"]\n")); err == nil {
+		t.Error("port with four parts accepted")
+	}
+}
+
+func TestServiceVolumeSyntax(t *testing.T) {
+	tests := []struct {
+		volume string
+		want   ServiceVolume
+	}{
+		{volume: "/var/lib/data", want: ServiceVolume{Type: "volume", Target: "/var/lib/data"}},
+		{volume: "data:/var/lib/data", want: ServiceVolume{Type: "volume", Source: "data", Target: "/var/lib/data"}},
+		{volume: "./conf:/etc/app:ro", want: ServiceVolume{Type: "bind", Source: "./conf", Target: "/etc/app", ReadOnly: true}},
+		{volume: "/share/Web:/srv:rw,z", want: ServiceVolume{Type: "bind", Source: "/share/Web", Target: "/srv"}},
+		{volume: "~/cache:/cache", want: ServiceVolume{Type: "bind", Source: "~/cache", Target: "/cache"}},
+		{
+			volume: "{type: tmpfs, target: /run, tmpfs: {size: 1000000}}",
+			want:   ServiceVolume{Type: "tmpfs", Target: "/run", Extras: map[string]any{"tmpfs": map[string]any{"size": 1000000}}},
+		},
+		{
+			volume: "{type: bind, source: /share/Data, target: /data, read_only: true}",
+			want:   ServiceVolume{Type: "bind", Source: "/share/Data", Target: "/data", ReadOnly: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.volume, func(t *testing.T) {
+			service := parseService(t, "    volumes:\n      - "+test.volume+"\n")
+			if len(service.Volumes) != 1 || !reflect.DeepEqual(service.Volumes[0], test.want) {
+				t.Errorf("volumes %+v, want %+v", service.Volumes, test.want)
+			}
+		})
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    ShellCommand
+	}{
+		{command: `nginx -g "daemon off;"`, want: ShellCommand{"nginx", "-g", "daemon off;"}},
+		{command: `sh -c 'echo "$$HOME" && sleep 1'`, want: ShellCommand{"sh", "-c", `echo "$$HOME" && sleep 1`}},
+		{command: `echo a\ b`, want: ShellCommand{"echo", "a b"}},
+		{command: `["sh", "-c", "echo hi"]`, want: ShellCommand{"sh", "-c", "echo hi"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command, func(t *testing.T) {
+			command := test.command
+			if !strings.HasPrefix(command, "[") {
+				command = "'" + strings.ReplaceAll(command, "'", "''") + "'"
+			}
+			service := parseService(t, "    command: "+command+"\n")
+			if !reflect.DeepEqual(service.Command, test.want) {
+				t.Errorf("command %q, want %q", service.Command, test.want)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("services:\n  app:\n    command: echo 'unterminated\n")); err == nil {
+		t.Error("unterminated quote accepted")
+	}
+}
+
+const stabilityFixture = `name: shop
+services:
+  web:
+    image: nginx:1.27
+    container_name: shop-web
+    command: nginx -g "daemon off;"
+    environment:
+      - TZ=UTC
+      - FROM_SHELL
+    labels:
+      traefik.enable: "true"
+    ports:
+      - "8080:80"
+      - "[::1]:8443:443/tcp"
+      - target: 9000
+        published: "9000"
+        mode: host
+    volumes:
+      - ./html:/usr/share/nginx/html:ro
+      - cache:/var/cache/nginx
+      - type: tmpfs
+        target: /run
+        tmpfs:
+          size: 1000000
+    networks: [frontend]
+    depends_on: [api]
+    restart: unless-stopped
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost/"]
+      interval: 30s
+  api:
+    build: ./api
+    environment:
+      DB_HOST: db
+    networks:
+      frontend:
+      backend:
+        ipv4_address: 10.10.0.5
+    depends_on:
+      db:
+        condition: service_healthy
+    deploy:
+      replicas: 1
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 512M
+        reservations:
+          memory: 128M
+  db:
+    image: postgres:16
+    volumes:
+      - db-data:/var/lib/postgresql/data
+    networks: [backend]
+networks:
+  frontend:
+  backend:
+    driver: bridge
+volumes:
+  cache:
+  db-data:
+    external: true
+x-qnap:
+  default_url: {service: web, port: 8080}
+`
+
+func TestParseMarshalStability(t *testing.T) {
+	project, err := Parse([]byte(stabilityFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := project.Validate(); err != nil {
+		t.Fatalf("fixture does not validate: %v", err)
+	}
+
+	first, err := project.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := Parse(first)
+	if err != nil {
+		t.Fatalf("Parse of marshalled project: %v\n%s", err, first)
+	}
+	if !reflect.DeepEqual(reparsed, project) {
+		t.Errorf("Parse -> Marshal -> Parse changed the project\nmarshalled:\n%s", first)
+	}
+
+	second, err := reparsed.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("Marshal is not stable\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	for _, kept := range []string{"build: ./api", "x-qnap:", "replicas: 1", "healthcheck:", "- frontend", "condition: service_healthy"} {
+		if !strings.Contains(string(first), kept) {
+			t.Errorf("marshalled project lost %q:\n%s", kept, first)
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	for _, data := range []string{"", "# only a comment\n"} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) accepted an empty file", data)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package compose
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Interpolate substitutes $VAR, ${VAR}, ${VAR:-default}, ${VAR-default}, ${VAR:?error}, ${VAR?error},
+// ${VAR:+replacement} and ${VAR+replacement} in a compose file using lookup.
+// Like compose, the file is parsed first and only scalar values are substituted, so a value containing
+// YAML syntax such as ": " or " #" stays one value and variables in comments and keys are left alone.
+// Escaped dollars ($$) are kept and dollars in substituted values are escaped, so the result can be
+// handed to compose again without a value such as pa$word being interpolated a second time
+func Interpolate(data []byte, lookup func(name string) (string, bool)) ([]byte, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		return data, nil
+	}
+
+	err = interpolateNode(&document, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&document)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// interpolateNode substitutes the variables of every scalar value below node. Mapping keys are kept
+// and aliases are skipped, their anchored node is substituted once where it is defined
+func interpolateNode(node *yaml.Node, lookup func(name string) (string, bool)) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			err := interpolateNode(child, lookup)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			err := interpolateNode(node.Content[i], lookup)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return nil
+		}
+		value, err := interpolateString(node.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if value == node.Value {
+			return nil
+		}
+		node.Value = value
+		// A plain ${PORT} is read as a number once substituted, as it would be written literally
+		if node.Style == 0 && node.Tag == "!!str" && isPlainNumberOrBool(value) {
+			node.Tag = ""
+		}
+	}
+	return nil
+}
+
+var plainNumberPattern = regexp.MustCompile(`^[-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?$`)
+
+// isPlainNumberOrBool reports whether a plain scalar with this text is read as a number or a boolean
+func isPlainNumberOrBool(value string) bool {
+	return value == "true" || value == "false" || plainNumberPattern.MatchString(value)
+}
+
+// interpolateString substitutes the variables of one value
+func interpolateString(text string, lookup func(name string) (string, bool)) (string, error) {
+	var result strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '$' || i+1 >= len(text) {
+			result.WriteByte(text[i])
+			continue
+		}
+
+		switch next := text[i+1]; {
+		case next == '$':
+			result.WriteString("$$")
+			i++
+		case next == '{':
+			end := matchingBrace(text, i+1)
+			if end < 0 {
+				return "", errors.New("unterminated ${ in compose file")
+			}
+			value, err := substitute(text[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(value)
+			i = end
+		case isNameStart(next):
+			end := i + 1
+			for end < len(text) && isNameChar(text[end]) {
+				end++
+			}
+			value, _ := lookup(text[i+1 : end])
+			result.WriteString(escapeDollars(value))
+			i = end - 1
+		default:
+			result.WriteByte('$')
+		}
+	}
+
+	return result.String(), nil
+}
+
+// escapeDollars escapes the dollars of a variable value so compose reads them literally
+func escapeDollars(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
+// substitute resolves the expression inside ${...}. Variable values are returned escaped, and so are
+// default and replacement words, whose own variables are substituted the same way
+func substitute(expression string, lookup func(name string) (string, bool)) (string, error) {
+	end := 0
+	for end < len(expression) && isNameChar(expression[end]) {
+		end++
+	}
+	name, operator := expression[:end], expression[end:]
+	if name == "" || !isNameStart(name[0]) {
+		return "", fmt.Errorf("invalid variable name in ${%s}", expression)
+	}
+
+	value, set := lookup(name)
+	escaped := escapeDollars(value)
+	if operator == "" {
+		return escaped, nil
+	}
+
+	// The text after the operator may itself contain variables
+	resolveWord := func(word string) (string, error) {
+		return interpolateString(word, lookup)
+	}
+
+	for _, op := range []string{":-", ":?", ":+", "-", "?", "+"} {
+		if !strings.HasPrefix(operator, op) {
+			continue
+		}
+		word := operator[len(op):]
+		empty := !set || (strings.HasPrefix(op, ":") && value == "")
+
+		switch op[len(op)-1] {
+		case '-':
+			if empty {
+				return resolveWord(word)
+			}
+			return escaped, nil
+		case '?':
+			if empty {
+				message, err := resolveWord(word)
+				if err != nil {
+					return "", err
+				}
+				if message == "" {
+					message = "is not set"
+				}
+				return "", fmt.Errorf("required variable %s %s", name, strings.ReplaceAll(message, "$$", "$"))
+			}
+			return escaped, nil
+		case '+':
+			if empty {
+				return "", nil
+			}
+			return resolveWord(word)
+		}
+	}
+	return "", fmt.Errorf("invalid substitution ${%s}", expression)
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or -1
+func matchingBrace(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// ReadEnvFile reads a compose .env file of KEY=VALUE lines. Blank lines, # comments and a leading
+// export are skipped, values may be single or double quoted and unquoted values end at an inline comment
+func ReadEnvFile(fileName string) (map[string]string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", fileName, lineNumber)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1])
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		env[key] = value
+	}
+	return env, scanner.Err()
+}
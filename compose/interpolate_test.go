@@ -0,0 +1,161 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{
+		"TAG":     "1.27",
+		"PORT":    "8080",
+		"OTHER":   "a: b",
+		"DB_PASS": "s3cret #1",
+		"EMPTY":   "",
+		"QUOTE":   `it's "quoted"`,
+		"DEBUG":   "true",
+		"PW":      "pa$word",
+	}
+
+	tests := []struct {
+		name string
+		yml  string
+		want string // The interpolated file, compared after decoding both
+	}{
+		{
+			name: "values with YAML syntax stay one value",
+			yml:  "environment:\n  OTHER: ${OTHER}\n  DB_PASS: $DB_PASS\n  QUOTE: ${QUOTE}\n",
+			want: "environment:\n  OTHER: \"a: b\"\n  DB_PASS: \"s3cret #1\"\n  QUOTE: 'it''s \"quoted\"'\n",
+		},
+		{
+			name: "variables in comments and keys are left alone",
+			yml:  "# needs ${REQUIRED:?must be set}\nlabels:\n  ${KEY}: value # ${ALSO_REQUIRED?}\n",
+			want: "labels:\n  ${KEY}: value\n",
+		},
+		{
+			name: "defaults, alternatives and partial values",
+			yml: "image: nginx:${TAG}\nfallback: ${UNSET:-x${TAG}}\nempty_default: ${EMPTY:-used}\n" +
+				"empty_kept: ${EMPTY-unused}\nalternative: ${TAG:+set}\nno_alternative: ${UNSET+set}\n",
+			want: "image: nginx:1.27\nfallback: x1.27\nempty_default: used\nempty_kept: \"\"\nalternative: set\nno_alternative: \"\"\n",
+		},
+		{
+			name: "plain values are typed as if written literally",
+			yml:  "ports:\n  - ${PORT}:80\npublished: ${PORT}\nquoted: \"${PORT}\"\nread_only: ${DEBUG}\n",
+			want: "ports:\n  - \"8080:80\"\npublished: 8080\nquoted: \"8080\"\nread_only: true\n",
+		},
+		{
+			name: "escaped dollars are kept",
+			yml:  "command: sh -c 'echo $$HOME ${TAG}'\ncost: 5$\n",
+			want: "command: sh -c 'echo $$HOME 1.27'\ncost: 5$\n",
+		},
+		{
+			name: "dollars in values are escaped so compose does not interpolate them again",
+			yml: "braced: ${PW}\nplain: $PW\ndefault: ${UNSET:-${PW}}\nset: ${PW:-unused}\n" +
+				"replacement: ${PW:+x$$y$PW}\nliteral_default: ${UNSET:-a$$b}\n",
+			want: "braced: pa$$word\nplain: pa$$word\ndefault: pa$$word\nset: pa$$word\n" +
+				"replacement: x$$ypa$$word\nliteral_default: a$$b\n",
+		},
+		{
+			name: "anchors are substituted once",
+			yml:  "x-env: &env\n  TAG: ${TAG}\nservices:\n  a:\n    environment: *env\n",
+			want: "x-env:\n  TAG: 1.27\nservices:\n  a:\n    environment:\n      TAG: 1.27\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Interpolate([]byte(test.yml), lookupFrom(env))
+			if err != nil {
+				t.Fatalf("Interpolate: %v", err)
+			}
+
+			var gotValue, wantValue any
+			if err := yaml.Unmarshal(got, &gotValue); err != nil {
+				t.Fatalf("interpolated file does not parse: %v\n%s", err, got)
+			}
+			if err := yaml.Unmarshal([]byte(test.want), &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("got:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	tests := []struct {
+		yml     string
+		problem string
+	}{
+		{yml: "a: 1\nb: ${REQUIRED:?must be set}\n", problem: "line 2: required variable REQUIRED must be set"},
+		{yml: "a: ${EMPTY:?}\n", problem: "line 1: required variable EMPTY is not set"},
+		{yml: "a: ${UNTERMINATED\n", problem: "line 1: unterminated ${"},
+		{yml: "a: ${1BAD}\n", problem: "invalid variable name"},
+		{yml: "a: ${NAME/x/y}\n", problem: "invalid substitution"},
+		{yml: "a: [unclosed\n", problem: "yaml"},
+	}
+
+	for _, test := range tests {
+		_, err := Interpolate([]byte(test.yml), lookupFrom(map[string]string{"EMPTY": "", "NAME": "n"}))
+		if err == nil || !strings.Contains(err.Error(), test.problem) {
+			t.Errorf("Interpolate(%q) error %v, want it to contain %q", test.yml, err, test.problem)
+		}
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	content := `# comment
+TZ=UTC
+export EXPORTED=yes
+SPACED = padded
+INLINE=value # comment
+HASH=a#b
+SINGLE='single # kept'
+DOUBLE="line\nbreak \"quoted\""
+EMPTY=
+OTHER=a: b
+`
+	if err := os.WriteFile(envFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := ReadEnvFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"TZ":       "UTC",
+		"EXPORTED": "yes",
+		"SPACED":   "padded",
+		"INLINE":   "value",
+		"HASH":     "a#b",
+		"SINGLE":   "single # kept",
+		"DOUBLE":   "line\nbreak \"quoted\"",
+		"EMPTY":    "",
+		"OTHER":    "a: b",
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("env %v, want %v", env, want)
+	}
+
+	if err := os.WriteFile(envFile, []byte("NOT A PAIR\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadEnvFile(envFile); err == nil || !strings.Contains(err.Error(), ":1: expected KEY=VALUE") {
+		t.Errorf("invalid line error %v", err)
+	}
+}
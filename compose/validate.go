@@ -0,0 +1,272 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	serviceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	restartPattern     = regexp.MustCompile(`^(no|always|unless-stopped|on-failure(:[0-9]+)?)$`)
+	memoryPattern      = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([bkmgtBKMGT]?)[bB]?$`)
+)
+
+// Validate checks the services, ports, volumes, networks, environment, dependencies and deploy limits
+// of the project and returns every problem found joined in one error. Values that still contain a
+// variable reference such as ${PORT} are left to Container Station, which interpolates them
+func (project *Project) Validate() error {
+	var problems []error
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if len(project.Services) == 0 {
+		addProblem("no services defined")
+	}
+
+	for _, name := range sortedKeys(project.Services) {
+		service := project.Services[name]
+		prefix := "service " + name
+
+		if !serviceNamePattern.MatchString(name) {
+			addProblem("%s: name must start with a letter or digit and contain only letters, digits, '_', '.' and '-'", prefix)
+		}
+		if service == nil {
+			addProblem("%s: definition is empty", prefix)
+			continue
+		}
+		if _, hasBuild := service.Extras["build"]; service.Image == "" && !hasBuild {
+			addProblem("%s: image or build is required", prefix)
+		}
+		if service.Restart != "" && !hasVariable(service.Restart) && !restartPattern.MatchString(service.Restart) {
+			addProblem("%s: restart policy %q is not no, always, on-failure[:max-retries] or unless-stopped", prefix, service.Restart)
+		}
+
+		for i, port := range service.Ports {
+			if hasVariable(port.Target, port.Published, port.HostIP, port.Protocol) {
+				continue
+			}
+			for _, err := range port.validate() {
+				addProblem("%s: port %d: %v", prefix, i, err)
+			}
+		}
+
+		targets := map[string]bool{}
+		for i, volume := range service.Volumes {
+			if hasVariable(volume.Source, volume.Target) {
+				continue
+			}
+			if !path.IsAbs(volume.Target) {
+				addProblem("%s: volume %d: target %q must be an absolute path", prefix, i, volume.Target)
+			} else if targets[path.Clean(volume.Target)] {
+				addProblem("%s: volume %d: target %s is mounted twice", prefix, i, volume.Target)
+			} else {
+				targets[path.Clean(volume.Target)] = true
+			}
+			switch volume.Type {
+			case "volume":
+				if _, declared := project.Volumes[volume.Source]; volume.Source != "" && !declared {
+					addProblem("%s: volume %d: named volume %s is not declared under volumes", prefix, i, volume.Source)
+				}
+			case "bind":
+				if volume.Source == "" {
+					addProblem("%s: volume %d: bind mount needs a source", prefix, i)
+				}
+			case "tmpfs", "npipe", "cluster":
+			default:
+				addProblem("%s: volume %d: type %q is not volume, bind or tmpfs", prefix, i, volume.Type)
+			}
+		}
+
+		for _, network := range sortedKeys(service.Networks) {
+			if _, declared := project.Networks[network]; network != "default" && !declared {
+				addProblem("%s: network %s is not declared under networks", prefix, network)
+			}
+			if settings := service.Networks[network]; settings != nil {
+				if settings.IPv4Address != "" && !hasVariable(settings.IPv4Address) && net.ParseIP(settings.IPv4Address).To4() == nil {
+					addProblem("%s: network %s: ipv4_address %q is not an IPv4 address", prefix, network, settings.IPv4Address)
+				}
+				if ip := net.ParseIP(settings.IPv6Address); settings.IPv6Address != "" && !hasVariable(settings.IPv6Address) && (ip == nil || ip.To4() != nil) {
+					addProblem("%s: network %s: ipv6_address %q is not an IPv6 address", prefix, network, settings.IPv6Address)
+				}
+			}
+		}
+
+		for key := range service.Environment {
+			if key == "" || strings.ContainsAny(key, "= \t") {
+				addProblem("%s: environment variable name %q is invalid", prefix, key)
+			}
+		}
+
+		for _, dependency := range sortedKeys(service.DependsOn) {
+			if dependency == name {
+				addProblem("%s: depends on itself", prefix)
+			} else if _, exists := project.Services[dependency]; !exists {
+				addProblem("%s: depends on undefined service %s", prefix, dependency)
+			}
+			condition := service.DependsOn[dependency].Condition
+			if !slices.Contains([]string{ConditionServiceStarted, ConditionServiceHealthy, ConditionServiceCompletedSuccessfully}, condition) {
+				addProblem("%s: depends_on %s: condition %q is not %s, %s or %s", prefix, dependency, condition,
+					ConditionServiceStarted, ConditionServiceHealthy, ConditionServiceCompletedSuccessfully)
+			}
+		}
+
+		if service.Deploy != nil {
+			resources := []struct {
+				kind     string
+				resource *Resource
+			}{
+				{"limits", service.Deploy.Resources.Limits},
+				{"reservations", service.Deploy.Resources.Reservations},
+			}
+			for _, entry := range resources {
+				kind, resource := entry.kind, entry.resource
+				if resource == nil {
+					continue
+				}
+				if resource.CPUs != "" && !hasVariable(resource.CPUs) {
+					if cpus, err := strconv.ParseFloat(resource.CPUs, 64); err != nil || cpus <= 0 {
+						addProblem("%s: deploy %s: cpus %q must be a positive number", prefix, kind, resource.CPUs)
+					}
+				}
+				if resource.Memory != "" && !hasVariable(resource.Memory) {
+					if _, err := ParseMemory(resource.Memory); err != nil {
+						addProblem("%s: deploy %s: %v", prefix, kind, err)
+					}
+				}
+			}
+		}
+	}
+
+	if cycle := project.dependencyCycle(); cycle != nil {
+		addProblem("depends_on cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	return errors.Join(problems...)
+}
+
+// hasVariable reports whether one of the values contains a variable reference
+func hasVariable(values ...string) bool {
+	for _, value := range values {
+		if strings.Contains(value, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the port numbers, protocol and host IP of a service port
+func (port ServicePort) validate() []error {
+	var problems []error
+
+	targetFrom, targetTo, err := parsePortRange(port.Target, 1)
+	if err != nil {
+		problems = append(problems, fmt.Errorf("target %q: %w", port.Target, err))
+	}
+	if port.Published != "" {
+		publishedFrom, publishedTo, err := parsePortRange(port.Published, 0)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("published %q: %w", port.Published, err))
+		} else if targetTo > targetFrom && publishedTo-publishedFrom != targetTo-targetFrom {
+			problems = append(problems, errors.New("published and target port ranges differ in size"))
+		}
+	}
+	if port.Protocol != "" && port.Protocol != "tcp" && port.Protocol != "udp" {
+		problems = append(problems, fmt.Errorf("protocol %q is not tcp or udp", port.Protocol))
+	}
+	if port.HostIP != "" && net.ParseIP(port.HostIP) == nil {
+		problems = append(problems, fmt.Errorf("host IP %q is not an IP address", port.HostIP))
+	}
+	return problems
+}
+
+// parsePortRange parses a port or a port range such as 8000-8010, ports must be between min and 65535
+func parsePortRange(value string, min int) (int, int, error) {
+	first, last, isRange := strings.Cut(value, "-")
+	from, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, 0, errors.New("not a port number")
+	}
+	to := from
+	if isRange {
+		to, err = strconv.Atoi(last)
+		if err != nil {
+			return 0, 0, errors.New("not a port range")
+		}
+	}
+	if from < min || to > 65535 || to < from {
+		return 0, 0, fmt.Errorf("ports must be between %d and 65535", min)
+	}
+	return from, to, nil
+}
+
+// dependencyCycle returns the services of a depends_on cycle, or nil when there is none
+func (project *Project) dependencyCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var stack []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		service := project.Services[name]
+		if service != nil {
+			for _, dependency := range sortedKeys(service.DependsOn) {
+				if _, exists := project.Services[dependency]; !exists || dependency == name {
+					continue
+				}
+				switch state[dependency] {
+				case visiting:
+					start := slices.Index(stack, dependency)
+					return append(append([]string(nil), stack[start:]...), dependency)
+				case unvisited:
+					if cycle := visit(dependency); cycle != nil {
+						return cycle
+					}
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, name := range sortedKeys(project.Services) {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// ParseMemory converts a memory size such as 512M, 1.5g, 64mb or 1024 (bytes) to bytes.
+// It accepts the size syntax of both compose files and docker run flags
+func ParseMemory(value string) (int64, error) {
+	match := memoryPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("memory %q must be a number with an optional b, k, m, g or t unit", value)
+	}
+
+	size, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	multiplier := map[string]float64{"": 1, "b": 1, "k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40}[strings.ToLower(match[2])]
+	return int64(math.Ceil(size * multiplier)), nil
+}
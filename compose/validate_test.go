@@ -0,0 +1,174 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		yml      string
+		problems []string // Expected substrings of the error, none for a valid project
+	}{
+		{
+			name: "valid",
+			yml: `services:
+  web:
+    image: nginx
+    ports: ["8080:80", "9000-9001:9000-9001/udp"]
+    volumes: ["data:/data", "./conf:/etc/nginx/conf.d:ro"]
+    depends_on: [api]
+    restart: on-failure:3
+  api:
+    build: .
+volumes:
+  data:
+`,
+		},
+		{
+			name: "unresolved variables are left to Container Station",
+			yml: `services:
+  web:
+    image: nginx:${TAG:-latest}
+    ports: ["${WEB_PORT:-8080}:80", "${BIND_IP}:443:443"]
+    volumes: ["${DATA_DIR}:/data"]
+    restart: ${RESTART_POLICY}
+    deploy:
+      resources:
+        limits: {cpus: "${CPUS}", memory: "${MEMORY}"}
+`,
+		},
+		{
+			name:     "no services",
+			yml:      "name: empty\n",
+			problems: []string{"no services defined"},
+		},
+		{
+			name: "service problems",
+			yml: `services:
+  -web:
+    restart: sometimes
+    ports: ["70000:80", "80:0", "8000-8001:80-82", "80/sctp", "300.0.0.1:80:80"]
+    volumes: ["relative:data", "undeclared:/data", "/a:/data"]
+    networks: [missing]
+    environment: ["BAD KEY=1"]
+    depends_on: [-web, ghost]
+    deploy:
+      resources:
+        limits: {cpus: "-1", memory: lots}
+`,
+			problems: []string{
+				"service -web: name must start with a letter or digit",
+				"service -web: image or build is required",
+				`restart policy "sometimes"`,
+				`port 0: published "70000": ports must be between 0 and 65535`,
+				`port 1: target "0": ports must be between 1 and 65535`,
+				"port 2: published and target port ranges differ in size",
+				`port 3: protocol "sctp" is not tcp or udp`,
+				`port 4: host IP "300.0.0.1" is not an IP address`,
+				`volume 0: target "data" must be an absolute path`,
+				"volume 1: named volume undeclared is not declared under volumes",
+				"volume 2: target /data is mounted twice",
+				"network missing is not declared under networks",
+				`environment variable name "BAD KEY" is invalid`,
+				"depends on itself",
+				"depends on undefined service ghost",
+				`deploy limits: cpus "-1" must be a positive number`,
+				`deploy limits: memory "lots"`,
+			},
+		},
+		{
+			name: "network addresses and conditions",
+			yml: `services:
+  web:
+    image: nginx
+    networks:
+      backend: {ipv4_address: "::1", ipv6_address: 10.0.0.1}
+    depends_on:
+      db: {condition: service_ready}
+  db:
+    image: postgres
+networks:
+  backend:
+`,
+			problems: []string{
+				`ipv4_address "::1" is not an IPv4 address`,
+				`ipv6_address "10.0.0.1" is not an IPv6 address`,
+				`condition "service_ready" is not service_started`,
+			},
+		},
+		{
+			name: "dependency cycle",
+			yml: `services:
+  a: {image: x, depends_on: [b]}
+  b: {image: x, depends_on: [c]}
+  c: {image: x, depends_on: [a]}
+  d: {image: x, depends_on: [a]}
+`,
+			problems: []string{"depends_on cycle: a -> b -> c -> a"},
+		},
+		{
+			name: "two service cycle with conditions",
+			yml: `services:
+  api: {image: x, depends_on: {db: {condition: service_healthy}}}
+  db: {image: x, depends_on: {api: {condition: service_started}}}
+`,
+			problems: []string{"depends_on cycle: api -> db -> api"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			project, err := Parse([]byte(test.yml))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			err = project.Validate()
+			if len(test.problems) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate accepted the project")
+			}
+			for _, problem := range test.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("error does not report %q:\n%v", problem, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{value: "1024", want: 1024},
+		{value: "512b", want: 512},
+		{value: "64k", want: 64 << 10},
+		{value: "512M", want: 512 << 20},
+		{value: "64mb", want: 64 << 20},
+		{value: "1.5g", want: 3 << 29},
+		{value: "2GB", want: 2 << 30},
+		{value: "1t", want: 1 << 40},
+		{value: "0.1k", want: 103},
+	}
+	for _, test := range tests {
+		got, err := ParseMemory(test.value)
+		if err != nil || got != test.want {
+			t.Errorf("ParseMemory(%q) = %d, %v, want %d", test.value, got, err, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "lots", "-1m", "1x", "1.m", "m"} {
+		if _, err := ParseMemory(value); err == nil {
+			t.Errorf("ParseMemory(%q) accepted", value)
+		}
+	}
+}
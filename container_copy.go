@@ -0,0 +1,275 @@
+package qnap
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CopyToContainer extracts the tar archive read from tarReader into destPath inside a container
+func (c *Client) CopyToContainer(ctx context.Context, containerID string, containerType string, destPath string, tarReader io.Reader, authToken *string) error {
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.archiveURL(containerID, containerType, destPath), tarReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+
+	stream, err := c.doStreamRequest(req, authToken)
+	if err != nil {
+		return err
+	}
+	return stream.Close()
+}
+
+// CopyFromContainer returns srcPath from inside a container as a tar archive, the caller must close it
+func (c *Client) CopyFromContainer(ctx context.Context, containerID string, containerType string, srcPath string, authToken *string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.archiveURL(containerID, containerType, srcPath), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doStreamRequest(req, authToken)
+}
+
+// CopyFileToContainer copies a local file or directory into destPath inside a container,
+// a directory is copied with its contents like docker cp does
+func (c *Client) CopyFileToContainer(ctx context.Context, containerID string, containerType string, localPath string, destPath string, authToken *string) error {
+	if _, err := os.Lstat(localPath); err != nil {
+		return err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(writeTar(pipeWriter, localPath))
+	}()
+	defer pipeReader.Close()
+
+	return c.CopyToContainer(ctx, containerID, containerType, destPath, pipeReader, authToken)
+}
+
+// CopyFileFromContainer copies a file or directory from inside a container into the local directory localDir
+func (c *Client) CopyFileFromContainer(ctx context.Context, containerID string, containerType string, srcPath string, localDir string, authToken *string) error {
+	stream, err := c.CopyFromContainer(ctx, containerID, containerType, srcPath, authToken)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return extractTar(stream, localDir)
+}
+
+func (c *Client) archiveURL(containerID string, containerType string, containerPath string) string {
+	query := url.Values{}
+	query.Set("id", containerID)
+	query.Set("path", containerPath)
+	return fmt.Sprintf("%s/container-station/api/v3/containers/%s/archive?%s", c.HostURL, containerType, query.Encode())
+}
+
+// writeTar writes localPath, and everything below it when it is a directory, as a tar archive
+// whose entries are named relative to the parent directory of localPath
+func writeTar(w io.Writer, localPath string) error {
+	tw := tar.NewWriter(w)
+	base := filepath.Dir(filepath.Clean(localPath))
+
+	err := filepath.WalkDir(localPath, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			link, err = os.Readlink(file)
+			if err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(base, file)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// extractTar writes the tar archive read from r below localDir, rejecting entries that escape it.
+// The archive comes from the container and is untrusted: entries are never written through a symbolic link,
+// and link targets are resolved against the links already extracted
+func extractTar(r io.Reader, localDir string) error {
+	err := os.MkdirAll(localDir, 0o755)
+	if err != nil {
+		return err
+	}
+	root, err := filepath.Abs(localDir)
+	if err != nil {
+		return err
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(header.Name))
+		if !withinDir(root, target) {
+			return errors.New("archive entry " + header.Name + " is outside the target directory")
+		}
+		if target == root {
+			continue
+		}
+		err = checkNoSymlinks(root, target)
+		if err != nil {
+			return errors.New("archive entry " + header.Name + " " + err.Error())
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, header.FileInfo().Mode().Perm()|0o700)
+		case tar.TypeReg:
+			err = writeFile(target, tr, header.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			err = os.MkdirAll(filepath.Dir(target), 0o755)
+			if err != nil {
+				return err
+			}
+			var linkTarget string
+			linkTarget, err = resolveLink(filepath.Dir(target), header.Linkname)
+			if err != nil {
+				return err
+			}
+			if filepath.IsAbs(header.Linkname) || !withinDir(root, linkTarget) {
+				return errors.New("archive link " + header.Name + " points outside the target directory")
+			}
+			err = os.Symlink(header.Linkname, target)
+		default:
+			// Devices, fifos and hard links are not restored
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// checkNoSymlinks returns an error when target or one of its parent directories below root is a symbolic link
+func checkNoSymlinks(root string, target string) error {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return err
+	}
+
+	current := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return errors.New("passes through the symbolic link " + current)
+		}
+	}
+	return nil
+}
+
+// resolveLink returns the path a link in dir pointing to linkname resolves to on disk.
+// Every existing component is resolved before the next one is applied, so ".." after a link
+// leaves the directory the link points to, as the kernel does
+func resolveLink(dir string, linkname string) (string, error) {
+	current := dir
+	for _, part := range strings.Split(filepath.ToSlash(linkname), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+		default:
+			current = filepath.Join(current, part)
+		}
+
+		resolved, err := filepath.EvalSymlinks(current)
+		if err == nil {
+			current = resolved
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return current, nil
+}
+
+func writeFile(target string, r io.Reader, perm fs.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(target), 0o755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// withinDir reports whether path is root or below it
+func withinDir(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
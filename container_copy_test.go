@@ -0,0 +1,159 @@
+package qnap
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Mode:     0o644,
+			Size:     int64(len(entry.body)),
+		}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0o755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTarRejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name:    "parent directory in name",
+			entries: []tarEntry{{name: "../escaped.txt", typeflag: tar.TypeReg, body: "x"}},
+		},
+		{
+			name:    "absolute link",
+			entries: []tarEntry{{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"}},
+		},
+		{
+			name:    "relative link to the parent",
+			entries: []tarEntry{{name: "link", typeflag: tar.TypeSymlink, linkname: "../"}},
+		},
+		{
+			name: "chained links",
+			entries: []tarEntry{
+				{name: "s2", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "s1", typeflag: tar.TypeSymlink, linkname: "s2/.."},
+				{name: "s1/escaped.txt", typeflag: tar.TypeReg, body: "x"},
+			},
+		},
+		{
+			name: "write through an in-tree link",
+			entries: []tarEntry{
+				{name: "dir", typeflag: tar.TypeDir},
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "dir"},
+				{name: "link/file.txt", typeflag: tar.TypeReg, body: "x"},
+			},
+		},
+		{
+			name: "overwrite an extracted link",
+			entries: []tarEntry{
+				{name: "file.txt", typeflag: tar.TypeReg, body: "x"},
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "file.txt"},
+				{name: "link", typeflag: tar.TypeReg, body: "y"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parent := t.TempDir()
+			localDir := filepath.Join(parent, "out")
+
+			err := extractTar(buildTar(t, test.entries), localDir)
+			if err == nil {
+				t.Fatal("extractTar accepted the archive")
+			}
+
+			if _, err := os.Lstat(filepath.Join(parent, "escaped.txt")); !os.IsNotExist(err) {
+				t.Errorf("escaped.txt was written outside the target directory")
+			}
+		})
+	}
+}
+
+func TestExtractTarRoundTrip(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "data")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "file.txt"), []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub/file.txt", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTar(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	if err := extractTar(&buf, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dst, "data", "sub", "file.txt"))
+	if err != nil || string(content) != "hello" {
+		t.Errorf("file content %q, %v", content, err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "data", "sub", "file.txt"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode %v, %v", info.Mode(), err)
+	}
+	link, err := os.Readlink(filepath.Join(dst, "data", "link"))
+	if err != nil || link != "sub/file.txt" {
+		t.Errorf("link %q, %v", link, err)
+	}
+}
+
+func TestExtractTarAllowsInTreeLinks(t *testing.T) {
+	entries := []tarEntry{
+		{name: "a/b", typeflag: tar.TypeDir},
+		{name: "a/b/up", typeflag: tar.TypeSymlink, linkname: "../../a"},
+		{name: "a/self", typeflag: tar.TypeSymlink, linkname: "."},
+	}
+
+	dst := t.TempDir()
+	if err := extractTar(buildTar(t, entries), dst); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := os.Readlink(filepath.Join(dst, "a", "b", "up"))
+	if err != nil || link != "../../a" {
+		t.Errorf("link %q, %v", link, err)
+	}
+}
@@ -0,0 +1,198 @@
+package qnap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// ExecOptions describes a command to run inside a container
+type ExecOptions struct {
+	Cmd     []string          `json:"cmd"`     // The command and its arguments
+	Env     map[string]string `json:"env"`     // Additional environment variables
+	User    string            `json:"user"`    // The user to run the command as, the container user when empty
+	WorkDir string            `json:"workdir"` // The working directory, the container default when empty
+	Tty     bool              `json:"tty"`     // Allocate a pseudo terminal, stderr is merged into stdout
+}
+
+// ExecResult represents the output of a command run inside a container
+type ExecResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int32  `json:"exitCode"`
+}
+
+// ExecContainer runs a command inside a container and waits for it to finish
+func (c *Client) ExecContainer(ctx context.Context, containerID string, containerType string, options ExecOptions, authToken *string) (*ExecResult, error) {
+	if len(options.Cmd) == 0 {
+		return nil, errors.New("exec command must not be empty")
+	}
+
+	rb, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/container-station/api/v3/containers/%s/exec?id=%s",
+		c.HostURL, containerType, containerID), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.doStreamRequest(req, authToken)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	body, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data ExecResult `json:"data"`
+	}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Data, nil
+}
+
+// ExecSession is an interactive terminal attached to a container through the Container Station websocket.
+// Reads return the terminal output, writes are sent as terminal input
+type ExecSession struct {
+	conn      *websocket.Conn
+	reader    io.Reader
+	writeMu   sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// ExecContainerInteractive opens an interactive terminal running options.Cmd inside a container, for example a shell.
+// The session stays open until it is closed, the command exits or ctx is cancelled
+func (c *Client) ExecContainerInteractive(ctx context.Context, containerID string, containerType string, options ExecOptions, authToken *string) (*ExecSession, error) {
+	if len(options.Cmd) == 0 {
+		return nil, errors.New("exec command must not be empty")
+	}
+
+	terminalURL, err := url.Parse(fmt.Sprintf("%s/container-station/api/v3/containers/%s/terminal", c.HostURL, containerType))
+	if err != nil {
+		return nil, err
+	}
+	switch terminalURL.Scheme {
+	case "https":
+		terminalURL.Scheme = "wss"
+	default:
+		terminalURL.Scheme = "ws"
+	}
+
+	query := url.Values{}
+	query.Set("id", containerID)
+	for _, arg := range options.Cmd {
+		query.Add("cmd", arg)
+	}
+	for key, value := range options.Env {
+		query.Add("env", key+"="+value)
+	}
+	if options.User != "" {
+		query.Set("user", options.User)
+	}
+	if options.WorkDir != "" {
+		query.Set("workdir", options.WorkDir)
+	}
+	query.Set("tty", "true")
+	terminalURL.RawQuery = query.Encode()
+
+	header := http.Header{}
+	if authToken != nil {
+		header.Set("Authorization", "Bearer "+strings.Split(*authToken, "=")[1])
+		header.Set("Cookie", *authToken)
+	}
+
+	dialer := *websocket.DefaultDialer
+	if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = transport.TLSClientConfig
+		dialer.Proxy = transport.Proxy
+	}
+
+	conn, res, err := dialer.DialContext(ctx, terminalURL.String(), header)
+	if err != nil {
+		if res != nil {
+			return nil, fmt.Errorf("status: %d, %w", res.StatusCode, err)
+		}
+		return nil, err
+	}
+
+	session := &ExecSession{conn: conn, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Close()
+		case <-session.done:
+		}
+	}()
+
+	return session, nil
+}
+
+// Read reads terminal output
+func (s *ExecSession) Read(p []byte) (int, error) {
+	for {
+		if s.reader == nil {
+			_, reader, err := s.conn.NextReader()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					return 0, io.EOF
+				}
+				return 0, err
+			}
+			s.reader = reader
+		}
+
+		n, err := s.reader.Read(p)
+		if err == io.EOF {
+			s.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Write sends terminal input
+func (s *ExecSession) Write(p []byte) (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	err := s.conn.WriteMessage(websocket.BinaryMessage, p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close ends the terminal session
+func (s *ExecSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.writeMu.Lock()
+		_ = s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		s.writeMu.Unlock()
+		s.closeErr = s.conn.Close()
+	})
+	return s.closeErr
+}
@@ -0,0 +1,44 @@
+package qnap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// LogOptions controls which container log lines are returned
+type LogOptions struct {
+	Tail       int       // Number of lines from the end of the log, 0 returns all lines
+	Since      time.Time // Only return lines written after this time, ignored when zero
+	Timestamps bool      // Prefix every line with its RFC3339Nano timestamp
+	Follow     bool      // Keep the stream open and return new lines until ctx is cancelled
+}
+
+// ContainerLogs returns the log stream of a container, the caller must close it.
+// In follow mode the stream stays open until ctx is cancelled or Container Station closes it
+func (c *Client) ContainerLogs(ctx context.Context, containerID string, containerType string, options LogOptions, authToken *string) (io.ReadCloser, error) {
+	query := url.Values{}
+	query.Set("id", containerID)
+	if options.Tail > 0 {
+		query.Set("tail", strconv.Itoa(options.Tail))
+	} else {
+		query.Set("tail", "all")
+	}
+	if !options.Since.IsZero() {
+		query.Set("since", strconv.FormatInt(options.Since.Unix(), 10))
+	}
+	query.Set("timestamps", strconv.FormatBool(options.Timestamps))
+	query.Set("follow", strconv.FormatBool(options.Follow))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/container-station/api/v3/containers/%s/logs?%s",
+		c.HostURL, containerType, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doStreamRequest(req, authToken)
+}
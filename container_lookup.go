@@ -0,0 +1,180 @@
+package qnap
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// ContainerFilter selects containers in ListContainers, empty fields match every container
+type ContainerFilter struct {
+	Status   string            // Container status, for example "running"
+	Type     string            // Container type, "docker" or "lxd"
+	Project  string            // Application (compose project) the container belongs to
+	Image    string            // Image reference, without a tag it matches every tag of the repository
+	Labels   map[string]string // Labels the container must carry, an empty value only requires the key
+	NameGlob string            // Shell pattern matched against the container name, for example "web-*"
+}
+
+// FindContainer returns the container with the given name
+func (c *Client) FindContainer(containerName string) (*Container, error) {
+	containers, err := c.GetContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, container := range containers {
+		if container.Name == containerName {
+			return &container, nil
+		}
+	}
+	return nil, errors.New("container " + containerName + " not found")
+}
+
+// ListContainers returns the containers matching the filter.
+// Labels are not part of the container list, so filtering on labels inspects every remaining candidate
+func (c *Client) ListContainers(filter ContainerFilter) ([]Container, error) {
+	if filter.NameGlob != "" {
+		if _, err := path.Match(filter.NameGlob, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	containers, err := c.GetContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Container
+	for _, container := range containers {
+		if filter.Status != "" && container.Status != filter.Status {
+			continue
+		}
+		if filter.Type != "" && container.Type != filter.Type {
+			continue
+		}
+		if filter.Project != "" && container.Project != filter.Project {
+			continue
+		}
+		if filter.Image != "" && !imageMatches(container.Image, filter.Image) {
+			continue
+		}
+		if filter.NameGlob != "" {
+			if matched, _ := path.Match(filter.NameGlob, container.Name); !matched {
+				continue
+			}
+		}
+		if len(filter.Labels) > 0 {
+			containerInfo, err := c.InspectContainer(container.ID, container.Type, &c.Token)
+			if err != nil {
+				return nil, err
+			}
+			if !labelsMatch(containerInfo.Data.Labels, filter.Labels) {
+				continue
+			}
+		}
+		matches = append(matches, container)
+	}
+	return matches, nil
+}
+
+// imageMatches reports whether image matches reference, a reference without tag or digest matches any tag
+func imageMatches(image string, reference string) bool {
+	if image == reference {
+		return true
+	}
+	if strings.Contains(reference, "@") || strings.LastIndex(reference, ":") > strings.LastIndex(reference, "/") {
+		return false
+	}
+	repository := image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	return repository == reference
+}
+
+// labelsMatch reports whether labels carries every wanted label
+func labelsMatch(labels map[string]string, wanted map[string]string) bool {
+	for key, value := range wanted {
+		actual, ok := labels[key]
+		if !ok || (value != "" && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// InspectContainerByName returns specific container specifications of the named container
+func (c *Client) InspectContainerByName(containerName string, authToken *string) (*ContainerInfo, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return nil, err
+	}
+	return c.InspectContainer(container.ID, container.Type, authToken)
+}
+
+// StartContainerByName starts the named container
+func (c *Client) StartContainerByName(containerName string, authToken *string) (bool, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return false, err
+	}
+	return c.StartContainer(container.ID, container.Type, authToken)
+}
+
+// StopContainerByName stops the named container
+func (c *Client) StopContainerByName(containerName string, authToken *string) (bool, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return false, err
+	}
+	return c.StopContainer(container.ID, container.Type, authToken)
+}
+
+// RestartContainerByName restarts the named container
+func (c *Client) RestartContainerByName(containerName string, authToken *string) (bool, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return false, err
+	}
+	return c.RestartContainer(container.ID, container.Type, authToken)
+}
+
+// PauseContainerByName pauses the named container
+func (c *Client) PauseContainerByName(containerName string, authToken *string) (bool, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return false, err
+	}
+	return c.PauseContainer(container.ID, container.Type, authToken)
+}
+
+// ResumeContainerByName resumes the named container
+func (c *Client) ResumeContainerByName(containerName string, authToken *string) (bool, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return false, err
+	}
+	return c.ResumeContainer(container.ID, container.Type, authToken)
+}
+
+// KillContainerByName sends a signal to the named container
+func (c *Client) KillContainerByName(containerName string, signal string, authToken *string) (bool, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return false, err
+	}
+	return c.KillContainer(container.ID, container.Type, signal, authToken)
+}
+
+// DeleteContainerByName deletes the named container
+func (c *Client) DeleteContainerByName(containerName string, containerVolumeRemove bool, authToken *string) (bool, error) {
+	container, err := c.FindContainer(containerName)
+	if err != nil {
+		return false, err
+	}
+	return c.DeleteContainer(container.ID, container.Type, containerVolumeRemove, authToken)
+}
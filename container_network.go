@@ -0,0 +1,97 @@
+package qnap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ConnectOptions describes how a container is attached to a network
+type ConnectOptions struct {
+	IPAddress string   // Static IP address on the network, assigned automatically when empty
+	Aliases   []string // Additional DNS names of the container on the network
+}
+
+// ContainerNetworkData - Payload for attaching or detaching a container network
+type ContainerNetworkData struct {
+	Network   string   `json:"network"`
+	IPAddress string   `json:"ipAddress,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+}
+
+// ConnectContainerNetwork attaches a running or stopped container to an additional network,
+// such as a bridge or a QNAP virtual switch, without recreating it
+func (c *Client) ConnectContainerNetwork(containerID string, containerType string, network string, options ConnectOptions, authToken *string) (bool, error) {
+	payload := ContainerNetworkData{
+		Network:   network,
+		IPAddress: options.IPAddress,
+		Aliases:   options.Aliases,
+	}
+
+	err := c.changeContainerNetwork(containerID, containerType, "connect", payload, authToken)
+	if err != nil {
+		return false, err
+	}
+
+	containerInfo, err := c.InspectContainer(containerID, containerType, authToken)
+	if err != nil {
+		return false, err
+	}
+
+	for _, containerNetwork := range containerInfo.Data.Networks {
+		if containerNetwork.Name == network || containerNetwork.ID == network {
+			if options.IPAddress != "" && containerNetwork.IPAddress != options.IPAddress {
+				return false, errors.New("container connected to network " + network + " with address " + containerNetwork.IPAddress + " instead of " + options.IPAddress)
+			}
+			return true, nil
+		}
+	}
+	return false, errors.New("container network connect failed to complete, network " + network + " not found on container")
+}
+
+// DisconnectContainerNetwork detaches a container from a network
+func (c *Client) DisconnectContainerNetwork(containerID string, containerType string, network string, authToken *string) (bool, error) {
+	err := c.changeContainerNetwork(containerID, containerType, "disconnect", ContainerNetworkData{Network: network}, authToken)
+	if err != nil {
+		return false, err
+	}
+
+	containerInfo, err := c.InspectContainer(containerID, containerType, authToken)
+	if err != nil {
+		return false, err
+	}
+
+	for _, containerNetwork := range containerInfo.Data.Networks {
+		if containerNetwork.Name == network || containerNetwork.ID == network {
+			return false, errors.New("container network disconnect failed to complete, network " + network + " still attached")
+		}
+	}
+	return true, nil
+}
+
+func (c *Client) changeContainerNetwork(containerID string, containerType string, operation string, payload ContainerNetworkData, authToken *string) error {
+	if payload.Network == "" {
+		return errors.New("network must not be empty")
+	}
+
+	rb, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/container-station/api/v3/containers/%s/networks/%s?id=%s",
+		c.HostURL, containerType, operation, containerID), strings.NewReader(string(rb)))
+	if err != nil {
+		return err
+	}
+
+	body, _, err := c.doRequest(req, authToken)
+	if err != nil {
+		return err
+	}
+
+	return c.waitForResponseTask(context.Background(), body)
+}
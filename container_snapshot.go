@@ -0,0 +1,77 @@
+package qnap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// CommitContainerData - Payload for committing a container to an image
+type CommitContainerData struct {
+	Repo    string `json:"repo"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// CommitContainer creates an image repo:tag from the current filesystem of a container
+func (c *Client) CommitContainer(containerID string, containerType string, repo string, tag string, message string, authToken *string) (bool, error) {
+	if repo == "" {
+		return false, errors.New("commit repository must not be empty")
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+
+	rb, err := json.Marshal(CommitContainerData{
+		Repo:    repo,
+		Tag:     tag,
+		Message: message,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/container-station/api/v3/containers/%s/commit?id=%s",
+		c.HostURL, containerType, containerID), strings.NewReader(string(rb)))
+	if err != nil {
+		return false, err
+	}
+
+	body, _, err := c.doRequest(req, authToken)
+	if err != nil {
+		return false, err
+	}
+
+	var response ContainerStationTaskResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return false, err
+	}
+
+	err = c.waitForTask(context.Background(), response.Data.TaskID)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ExportContainer streams the filesystem of a container as a tar archive to w and returns the number of bytes written
+func (c *Client) ExportContainer(ctx context.Context, containerID string, containerType string, w io.Writer, authToken *string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/container-station/api/v3/containers/%s/export?id=%s",
+		c.HostURL, containerType, containerID), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	stream, err := c.doStreamRequest(req, authToken)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	return io.Copy(w, stream)
+}
@@ -0,0 +1,294 @@
+package qnap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ToSpec converts the inspected container back into a NewContainerSpec that recreates the same container.
+// The first network is used as the container network, its address is kept only when it is static.
+// A spec holds a single network, attach the other networks after creation with ConnectAdditionalNetworks
+func (info *ContainerInfo) ToSpec() NewContainerSpec {
+	data := info.Data
+
+	spec := NewContainerSpec{
+		Type:           data.Type,
+		Name:           data.Name,
+		Image:          data.Image,
+		AutoRemove:     data.AutoRemove,
+		Cmd:            append([]string(nil), data.Cmd...),
+		Entrypoint:     append([]string(nil), data.Entrypoint...),
+		Tty:            data.Tty,
+		OpenStdin:      data.OpenStdin,
+		Hostname:       data.Hostname,
+		DNS:            append([]string(nil), data.DNS...),
+		Env:            copyStringMap(data.Env),
+		Labels:         copyStringMap(data.Labels),
+		Runtime:        data.Runtime,
+		Privileged:     data.Privileged,
+		CPULimit:       data.CPULimit,
+		MemLimit:       data.MemLimit,
+		MemReservation: data.MemReservation,
+		CapAdd:         append([]string(nil), data.CapAdd...),
+		CapDrop:        append([]string(nil), data.CapDrop...),
+		SecurityOpt:    append([]string(nil), data.SecurityOpt...),
+		Ulimits:        append([]Ulimit(nil), data.Ulimits...),
+		ShmSize:        data.ShmSize,
+		User:           data.User,
+		WorkingDir:     data.WorkingDir,
+		Sysctls:        copyStringMap(data.Sysctls),
+		Tmpfs:          copyStringMap(data.Tmpfs),
+		ReadonlyRootfs: data.ReadonlyRootfs,
+		StopSignal:     data.StopSignal,
+		StopTimeout:    data.StopTimeout,
+		Cpupin: Cpupin{
+			CPUIDs: data.Cpupin.CPUIDs,
+			Type:   data.Cpupin.Type,
+		},
+		RestartPolicy: RestartPolicy{
+			Name:              data.RestartPolicy.Name,
+			MaximumRetryCount: data.RestartPolicy.MaximumRetryCount,
+		},
+	}
+
+	if len(data.Networks) > 0 {
+		network := data.Networks[0]
+		spec.Network = network.Name
+		spec.NetworkType = network.NetworkType
+		if network.IsStaticIP {
+			spec.IPAddress = network.IPAddress
+		}
+	}
+
+	if data.Healthcheck != nil {
+		healthcheck := *data.Healthcheck
+		healthcheck.Test = append([]string(nil), healthcheck.Test...)
+		spec.Healthcheck = &healthcheck
+	}
+
+	for _, device := range data.Devices {
+		spec.Devices = append(spec.Devices, Devices{
+			Name:       device.Name,
+			Permission: device.Permission,
+		})
+	}
+
+	for _, volume := range data.Volumes {
+		spec.Volumes = append(spec.Volumes, Volumes{
+			Type:        volume.Type,
+			Name:        volume.Name,
+			Container:   volume.Container,
+			Source:      volume.Source,
+			Destination: volume.Destination,
+			Permission:  volume.Permission,
+		})
+	}
+
+	for _, portBinding := range data.PortBindings {
+		spec.PortBindings = append(spec.PortBindings, PortBindings{
+			Host:        portBinding.Host,
+			Container:   portBinding.Container,
+			Protocol:    portBinding.Protocol,
+			HostIP:      portBinding.HostIP,
+			ContainerIP: portBinding.ContainerIP,
+		})
+	}
+
+	return spec
+}
+
+// ConnectAdditionalNetworks attaches a container created from info.ToSpec() to the networks of info
+// after the first one, which the spec leaves out. Static addresses are kept
+func (c *Client) ConnectAdditionalNetworks(containerID string, containerType string, info *ContainerInfo, authToken *string) error {
+	networks := info.Data.Networks
+	for i := 1; i < len(networks); i++ {
+		var options ConnectOptions
+		if networks[i].IsStaticIP {
+			options.IPAddress = networks[i].IPAddress
+		}
+		_, err := c.ConnectContainerNetwork(containerID, containerType, networks[i].Name, options, authToken)
+		if err != nil {
+			return fmt.Errorf("connect network %s: %w", networks[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// copyStringMap returns a copy of m, nil stays nil
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+var (
+	containerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	imageDomainPattern   = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?$`)
+	imagePathPattern     = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	imageTagPattern      = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	imageDigestPattern   = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+	cpuIDsPattern        = regexp.MustCompile(`^[0-9]+(?:-[0-9]+)?(?:,[0-9]+(?:-[0-9]+)?)*$`)
+)
+
+// Values accepted by Validate for the enumerated NewContainerSpec fields
+var (
+	portProtocols      = []string{"tcp", "udp"}
+	restartPolicyNames = []string{"", "no", "always", "on-failure", "unless-stopped"}
+	volumeTypes        = []string{"new", "volume", "host", "bind"}
+	volumePermissions  = []string{"", "rw", "ro"}
+)
+
+// Validate checks the spec before it is submitted and returns every problem found joined in one error
+func (spec *NewContainerSpec) Validate() error {
+	var problems []error
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if !containerNamePattern.MatchString(spec.Name) {
+		addProblem("name %q must start with a letter or digit and contain only letters, digits, '_', '.' and '-'", spec.Name)
+	}
+
+	if err := validateImageReference(spec.Image); err != nil {
+		addProblem("image: %v", err)
+	}
+
+	for i, portBinding := range spec.PortBindings {
+		if portBinding.Container < 1 || portBinding.Container > 65535 {
+			addProblem("port binding %d: container port %d is not between 1 and 65535", i, portBinding.Container)
+		}
+		if portBinding.Host < 0 || portBinding.Host > 65535 {
+			addProblem("port binding %d: host port %d is not between 0 and 65535", i, portBinding.Host)
+		}
+		if !slices.Contains(portProtocols, strings.ToLower(portBinding.Protocol)) {
+			addProblem("port binding %d: protocol %q is not one of %s", i, portBinding.Protocol, strings.Join(portProtocols, ", "))
+		}
+		if portBinding.HostIP != "" && net.ParseIP(portBinding.HostIP) == nil {
+			addProblem("port binding %d: host IP %q is not an IP address", i, portBinding.HostIP)
+		}
+	}
+
+	if !slices.Contains(restartPolicyNames, spec.RestartPolicy.Name) {
+		addProblem("restart policy %q is not one of %s", spec.RestartPolicy.Name, strings.Join(restartPolicyNames[1:], ", "))
+	}
+	if spec.RestartPolicy.MaximumRetryCount < 0 {
+		addProblem("restart policy maximum retry count %d must not be negative", spec.RestartPolicy.MaximumRetryCount)
+	} else if spec.RestartPolicy.MaximumRetryCount > 0 && spec.RestartPolicy.Name != "on-failure" {
+		addProblem("restart policy maximum retry count is only supported with on-failure")
+	}
+
+	if err := validateCPUIDs(spec.Cpupin.CPUIDs); err != nil {
+		addProblem("cpupin: %v", err)
+	}
+
+	destinations := make(map[string]int)
+	for i, volume := range spec.Volumes {
+		if !slices.Contains(volumeTypes, volume.Type) {
+			addProblem("volume %d: type %q is not one of %s", i, volume.Type, strings.Join(volumeTypes, ", "))
+		}
+		if !slices.Contains(volumePermissions, volume.Permission) {
+			addProblem("volume %d: permission %q is not one of %s", i, volume.Permission, strings.Join(volumePermissions[1:], ", "))
+		}
+		if volume.Destination == "" && volume.Type == "new" {
+			// Container Station picks the mount point of a new volume
+			continue
+		}
+		if !path.IsAbs(volume.Destination) {
+			addProblem("volume %d: destination %q must be an absolute path", i, volume.Destination)
+			continue
+		}
+		destination := path.Clean(volume.Destination)
+		if first, ok := destinations[destination]; ok {
+			addProblem("volume %d: destination %s is already used by volume %d", i, destination, first)
+		} else {
+			destinations[destination] = i
+		}
+	}
+
+	for _, ulimit := range spec.Ulimits {
+		if ulimit.Name == "" {
+			addProblem("ulimit name must not be empty")
+		} else if ulimit.Hard >= 0 && ulimit.Soft > ulimit.Hard {
+			addProblem("ulimit %s: soft limit %d exceeds hard limit %d", ulimit.Name, ulimit.Soft, ulimit.Hard)
+		}
+	}
+	for mountPath := range spec.Tmpfs {
+		if !path.IsAbs(mountPath) {
+			addProblem("tmpfs mount %q must be an absolute path", mountPath)
+		}
+	}
+	if spec.ShmSize < 0 {
+		addProblem("shm size %d must not be negative", spec.ShmSize)
+	}
+	if spec.StopTimeout < 0 {
+		addProblem("stop timeout %d must not be negative", spec.StopTimeout)
+	}
+
+	return errors.Join(problems...)
+}
+
+// validateImageReference checks an image reference of the form [domain/]path[:tag][@digest]
+func validateImageReference(image string) error {
+	if image == "" {
+		return errors.New("reference must not be empty")
+	}
+
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		if !imageDigestPattern.MatchString(name[i+1:]) {
+			return fmt.Errorf("invalid digest in %q", image)
+		}
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		if !imageTagPattern.MatchString(name[i+1:]) {
+			return fmt.Errorf("invalid tag in %q", image)
+		}
+		name = name[:i]
+	}
+
+	components := strings.Split(name, "/")
+	if len(components) > 1 && (strings.ContainsAny(components[0], ".:") || components[0] == "localhost") {
+		if !imageDomainPattern.MatchString(components[0]) {
+			return fmt.Errorf("invalid registry in %q", image)
+		}
+		components = components[1:]
+	}
+	for _, component := range components {
+		if !imagePathPattern.MatchString(component) {
+			return fmt.Errorf("invalid repository name in %q", image)
+		}
+	}
+	return nil
+}
+
+// validateCPUIDs checks a CPU list such as "0,2-3", an empty list is valid
+func validateCPUIDs(cpuIDs string) error {
+	if cpuIDs == "" {
+		return nil
+	}
+	if !cpuIDsPattern.MatchString(cpuIDs) {
+		return fmt.Errorf("CPU IDs %q must be a comma separated list of CPUs or ranges such as 0,2-3", cpuIDs)
+	}
+	for _, cpuRange := range strings.Split(cpuIDs, ",") {
+		if first, last, ok := strings.Cut(cpuRange, "-"); ok {
+			from, _ := strconv.Atoi(first)
+			to, _ := strconv.Atoi(last)
+			if from > to {
+				return fmt.Errorf("CPU range %s is descending", cpuRange)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package qnap
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestContainerInfoToSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		inspect string
+		create  string
+	}{
+		{
+			name: "full container",
+			inspect: `{"data": {
+				"id": "3f2a9c1d", "name": "web", "type": "docker", "image": "nginx:1.27", "imageID": "sha256:5ef79149e0ec",
+				"status": "running", "cpuLimit": 150, "memLimit": 512, "memReservation": 256,
+				"cpupin": {"cpuids": "0-1", "type": "dedicated"},
+				"networks": [{"id": "a1b2", "name": "bridge", "ipAddress": "172.17.0.5", "displayName": "bridge",
+					"macAddress": "02:42:ac:11:00:05", "gateway": "172.17.0.1", "networkType": "default", "isStaticIP": false}],
+				"project": "", "uuid": "6b1f0c2e", "runtime": "runc", "created": "2024-05-01T10:00:00Z",
+				"startedAt": "2024-05-01T10:00:01Z", "finishedAt": "0001-01-01T00:00:00Z",
+				"cmd": ["nginx", "-g", "daemon off;"], "dns": ["1.1.1.1"], "exposedPorts": ["80/tcp"], "pid": 1234,
+				"portBindings": [{"host": 8080, "container": 80, "protocol": "tcp", "hostIP": "0.0.0.0", "containerIP": ""}],
+				"devices": [{"name": "/dev/dri", "permission": "rwm"}],
+				"restartPolicy": {"name": "on-failure", "maximumRetryCount": 3},
+				"entrypoint": ["/docker-entrypoint.sh"], "privileged": false,
+				"env": {"TZ": "UTC"}, "labels": {"app": "web"},
+				"volumes": [{"type": "volume", "name": "web-data", "container": "", "source": "", "destination": "/usr/share/nginx/html", "permission": "rw"},
+					{"type": "host", "name": "", "container": "", "source": "/share/Web/conf", "destination": "/etc/nginx/conf.d", "permission": "ro"}],
+				"autoRemove": false, "hostname": "web", "cpu": 1.5, "memory": 42.1, "tx": 1024, "rx": 2048, "read": 0, "write": 0,
+				"tty": false, "openStdin": false,
+				"healthcheck": {"test": ["CMD-SHELL", "curl -f http://localhost/ || exit 1"], "interval": 30000000000,
+					"timeout": 5000000000, "retries": 3, "startperiod": 10000000000},
+				"dockerStatus": {"running": true, "paused": false, "restarting": false, "dead": false, "exitCode": 0,
+					"startedAt": "2024-05-01T10:00:01Z", "finishedAt": "0001-01-01T00:00:00Z", "health": "healthy"},
+				"capAdd": ["NET_BIND_SERVICE"], "capDrop": ["ALL"], "securityOpt": ["no-new-privileges"],
+				"ulimits": [{"name": "nofile", "soft": 1024, "hard": 2048}], "shmSize": 67108864,
+				"user": "101:101", "workingDir": "/srv", "sysctls": {"net.core.somaxconn": "1024"},
+				"tmpfs": {"/run": "size=64m"}, "readonlyRootfs": true, "stopSignal": "SIGQUIT", "stopTimeout": 20
+			}}`,
+			create: `{
+				"type": "docker", "name": "web", "image": "nginx:1.27", "autoremove": false,
+				"cmd": ["nginx", "-g", "daemon off;"], "entrypoint": ["/docker-entrypoint.sh"],
+				"tty": false, "openstdin": false, "pull": false, "network": "bridge", "networktype": "default",
+				"hostname": "web", "dns": ["1.1.1.1"], "env": {"TZ": "UTC"}, "labels": {"app": "web"},
+				"runtime": "runc", "privileged": false, "operation": "", "ipAddress": "",
+				"devices": [{"name": "/dev/dri", "permission": "rwm"}],
+				"volumes": [{"type": "volume", "name": "web-data", "container": "", "source": "", "destination": "/usr/share/nginx/html", "permission": "rw"},
+					{"type": "host", "name": "", "container": "", "source": "/share/Web/conf", "destination": "/etc/nginx/conf.d", "permission": "ro"}],
+				"portbindings": [{"host": 8080, "container": 80, "protocol": "tcp", "hostip": "0.0.0.0", "containerip": ""}],
+				"cpupin": {"cpuids": "0-1", "type": "dedicated"},
+				"restartpolicy": {"name": "on-failure", "maximumretrycount": 3},
+				"cpulimit": 150, "memlimit": 512, "memreservation": 256,
+				"healthcheck": {"test": ["CMD-SHELL", "curl -f http://localhost/ || exit 1"], "interval": 30000000000,
+					"timeout": 5000000000, "retries": 3, "startperiod": 10000000000},
+				"capadd": ["NET_BIND_SERVICE"], "capdrop": ["ALL"], "securityopt": ["no-new-privileges"],
+				"ulimits": [{"name": "nofile", "soft": 1024, "hard": 2048}], "shmsize": 67108864,
+				"user": "101:101", "workingdir": "/srv", "sysctls": {"net.core.somaxconn": "1024"},
+				"tmpfs": {"/run": "size=64m"}, "readonlyrootfs": true, "stopsignal": "SIGQUIT", "stoptimeout": 20
+			}`,
+		},
+		{
+			name: "static address on the first of several networks",
+			inspect: `{"data": {
+				"id": "77ab", "name": "dns", "type": "docker", "image": "registry.example.com:5000/infra/coredns:1.11.1", "status": "exited",
+				"cpuLimit": 0, "memLimit": 0, "memReservation": 0, "cpupin": {"cpuids": "", "type": ""},
+				"networks": [{"id": "c3d4", "name": "qnet-static-eth0", "ipAddress": "192.168.1.53", "displayName": "Virtual Switch 1",
+						"macAddress": "02:42:c0:a8:01:35", "gateway": "192.168.1.1", "networkType": "qnet", "isStaticIP": true},
+					{"id": "e5f6", "name": "backend", "ipAddress": "10.10.0.4", "displayName": "backend",
+						"macAddress": "02:42:0a:0a:00:04", "gateway": "10.10.0.1", "networkType": "default", "isStaticIP": false}],
+				"runtime": "runc", "cmd": ["-conf", "/etc/coredns/Corefile"], "dns": null, "portBindings": [],
+				"devices": [], "restartPolicy": {"name": "always", "maximumRetryCount": 0},
+				"entrypoint": ["/coredns"], "privileged": false, "env": {}, "labels": null, "volumes": [],
+				"autoRemove": false, "hostname": "dns", "tty": false, "openStdin": false, "healthcheck": null,
+				"dockerStatus": {"running": false, "exitCode": 0, "health": ""}
+			}}`,
+			create: `{
+				"type": "docker", "name": "dns", "image": "registry.example.com:5000/infra/coredns:1.11.1", "autoremove": false,
+				"cmd": ["-conf", "/etc/coredns/Corefile"], "entrypoint": ["/coredns"],
+				"tty": false, "openstdin": false, "pull": false, "network": "qnet-static-eth0", "networktype": "qnet",
+				"hostname": "dns", "dns": null, "env": {}, "labels": null,
+				"runtime": "runc", "privileged": false, "operation": "", "ipAddress": "192.168.1.53",
+				"devices": null, "volumes": null, "portbindings": null,
+				"cpupin": {"cpuids": "", "type": ""},
+				"restartpolicy": {"name": "always", "maximumretrycount": 0}
+			}`,
+		},
+		{
+			name: "no network",
+			inspect: `{"data": {
+				"id": "9e8d", "name": "batch", "type": "docker", "image": "alpine", "status": "created",
+				"networks": [], "cmd": ["sh", "-c", "echo done"], "restartPolicy": {"name": "no", "maximumRetryCount": 0},
+				"autoRemove": true, "tty": true, "openStdin": true
+			}}`,
+			create: `{
+				"type": "docker", "name": "batch", "image": "alpine", "autoremove": true,
+				"cmd": ["sh", "-c", "echo done"], "entrypoint": null,
+				"tty": true, "openstdin": true, "pull": false, "network": "", "networktype": "",
+				"hostname": "", "dns": null, "env": null, "labels": null,
+				"runtime": "", "privileged": false, "operation": "", "ipAddress": "",
+				"devices": null, "volumes": null, "portbindings": null,
+				"cpupin": {"cpuids": "", "type": ""},
+				"restartpolicy": {"name": "no", "maximumretrycount": 0}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var info ContainerInfo
+			if err := json.Unmarshal([]byte(test.inspect), &info); err != nil {
+				t.Fatalf("unmarshal inspect payload: %v", err)
+			}
+
+			spec := info.ToSpec()
+			if err := spec.Validate(); err != nil {
+				t.Errorf("spec of an existing container does not validate: %v", err)
+			}
+
+			rb, err := json.Marshal(spec)
+			if err != nil {
+				t.Fatalf("marshal spec: %v", err)
+			}
+
+			var got, want any
+			if err := json.Unmarshal(rb, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(test.create), &want); err != nil {
+				t.Fatalf("unmarshal expected create payload: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("create payload mismatch\ngot:  %s\nwant: %s", rb, test.create)
+			}
+		})
+	}
+}
+
+func TestContainerInfoToSpecCopies(t *testing.T) {
+	var info ContainerInfo
+	info.Data.Cmd = []string{"run"}
+	info.Data.Env = map[string]string{"A": "1"}
+	info.Data.Healthcheck = &Healthcheck{Test: []string{"CMD", "true"}}
+
+	spec := info.ToSpec()
+	spec.Cmd[0] = "changed"
+	spec.Env["A"] = "changed"
+	spec.Healthcheck.Test[1] = "false"
+
+	if info.Data.Cmd[0] != "run" || info.Data.Env["A"] != "1" || info.Data.Healthcheck.Test[1] != "true" {
+		t.Errorf("modifying the spec changed the inspected container: %+v", info.Data)
+	}
+}
+
+// TestContainerRoundTrip creates a container, rebuilds its spec with ToSpec and recreates it from that spec.
+// The spec must equal the one the container was created from, and the recreated container the original
+func TestContainerRoundTrip(t *testing.T) {
+	station := newFakeStation(t)
+	client := station.client()
+
+	original := NewContainerSpec{
+		Type: "docker", Name: "web", Image: "nginx:1.27",
+		Cmd: []string{"nginx", "-g", "daemon off;"}, Entrypoint: []string{"/docker-entrypoint.sh"},
+		Network: "qnet-static-eth0", NetworkType: "qnet", IPAddress: "192.168.1.80",
+		Hostname: "web", DNS: []string{"1.1.1.1"},
+		Env: map[string]string{"TZ": "UTC"}, Labels: map[string]string{"app": "web"},
+		Runtime: "runc",
+		Devices: []Devices{{Name: "/dev/dri", Permission: "rwm"}},
+		Volumes: []Volumes{
+			{Type: "volume", Name: "web-data", Destination: "/usr/share/nginx/html", Permission: "rw"},
+			{Type: "host", Source: "/share/Web/conf", Destination: "/etc/nginx/conf.d", Permission: "ro"},
+		},
+		PortBindings:   []PortBindings{{Host: 8080, Container: 80, Protocol: "tcp", HostIP: "0.0.0.0"}},
+		Cpupin:         Cpupin{CPUIDs: "0-1", Type: "dedicated"},
+		RestartPolicy:  RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+		CPULimit:       150,
+		MemLimit:       512,
+		MemReservation: 256,
+		Healthcheck:    &Healthcheck{Test: []string{"CMD-SHELL", "curl -f http://localhost/ || exit 1"}, Interval: 30000000000, Retries: 3},
+		CapAdd:         []string{"NET_BIND_SERVICE"}, CapDrop: []string{"ALL"}, SecurityOpt: []string{"no-new-privileges"},
+		Ulimits: []Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}}, ShmSize: 64 << 20,
+		User: "101:101", WorkingDir: "/srv", Sysctls: map[string]string{"net.core.somaxconn": "1024"},
+		Tmpfs: map[string]string{"/run": "size=64m"}, ReadonlyRootfs: true, StopSignal: "SIGQUIT", StopTimeout: 20,
+	}
+
+	created, err := client.CreateContainer(original, &client.Token)
+	if err != nil {
+		t.Fatalf("CreateContainer: %v", err)
+	}
+	if _, err := client.ConnectContainerNetwork(created.Data.ID, "docker", "backend", ConnectOptions{}, &client.Token); err != nil {
+		t.Fatalf("connect backend: %v", err)
+	}
+	if _, err := client.ConnectContainerNetwork(created.Data.ID, "docker", "storage", ConnectOptions{IPAddress: "10.20.0.9"}, &client.Token); err != nil {
+		t.Fatalf("connect storage: %v", err)
+	}
+	before, err := client.InspectContainer(created.Data.ID, "docker", &client.Token)
+	if err != nil {
+		t.Fatalf("InspectContainer: %v", err)
+	}
+
+	spec := before.ToSpec()
+	if !reflect.DeepEqual(spec, original) {
+		t.Errorf("ToSpec of the created container\ngot:  %+v\nwant: %+v", spec, original)
+	}
+
+	spec.Operation = "recreate"
+	recreated, err := client.CreateContainer(spec, &client.Token)
+	if err != nil {
+		t.Fatalf("recreate from the spec: %v", err)
+	}
+	if err := client.ConnectAdditionalNetworks(recreated.Data.ID, "docker", before, &client.Token); err != nil {
+		t.Fatalf("ConnectAdditionalNetworks: %v", err)
+	}
+	after, err := client.InspectContainer(recreated.Data.ID, "docker", &client.Token)
+	if err != nil {
+		t.Fatalf("InspectContainer: %v", err)
+	}
+
+	// Only the ID and the addresses assigned on networks without a static address may differ
+	normalize := func(info *ContainerInfo) {
+		info.Data.ID = ""
+		for i := range info.Data.Networks {
+			if !info.Data.Networks[i].IsStaticIP {
+				info.Data.Networks[i].IPAddress = ""
+			}
+		}
+	}
+	if after.Data.ID == before.Data.ID {
+		t.Errorf("container %s was not recreated", after.Data.ID)
+	}
+	normalize(before)
+	normalize(after)
+	if len(after.Data.Networks) != 3 {
+		t.Errorf("recreated container has %d networks, want 3", len(after.Data.Networks))
+	}
+	if !reflect.DeepEqual(after.Data, before.Data) {
+		t.Errorf("recreated container differs\ngot:  %+v\nwant: %+v", after.Data, before.Data)
+	}
+}
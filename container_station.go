@@ -1,15 +1,13 @@
 package qnap
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
-var data struct {
-	ContainerStationOverview
-}
-
 // GetContainerStationOverview  - Returns all containers and apps running inside container station
 func (c *Client) GetContainerStationOverview() (*ContainerStationOverview, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/container-station/api/v3/overview", c.HostURL), nil)
@@ -22,11 +20,12 @@ func (c *Client) GetContainerStationOverview() (*ContainerStationOverview, error
 		return nil, err
 	}
 
+	var data ContainerStationOverview
 	err = json.Unmarshal(body, &data)
 	if err != nil {
 		return nil, err
 	}
-	return &data.ContainerStationOverview, nil
+	return &data, nil
 }
 
 // Get task status
@@ -53,3 +52,33 @@ func (c *Client) GetTaskStatus(taskID string) (string, error) {
 	}
 	return "not-found", nil
 }
+
+// waitForTask polls the task list until the task is completed or ctx is done
+func (c *Client) waitForTask(ctx context.Context, taskID string) error {
+	for {
+		taskStatus, err := c.GetTaskStatus(taskID)
+		if err != nil {
+			return err
+		}
+
+		if taskStatus == TaskStatusCompleted {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// waitForResponseTask waits for the task referenced by a response body, responses of
+// operations Container Station applies synchronously carry no task and return straight away
+func (c *Client) waitForResponseTask(ctx context.Context, body []byte) error {
+	var response ContainerStationTaskResponse
+	if json.Unmarshal(body, &response) != nil || response.Data.TaskID == "" {
+		return nil
+	}
+	return c.waitForTask(ctx, response.Data.TaskID)
+}
@@ -0,0 +1,132 @@
+package qnap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ContainerStats represents a resource usage sample of a container
+type ContainerStats struct {
+	Time      time.Time // When the sample was taken
+	CPU       float32   // CPU usage as reported by Container Station
+	Memory    float32   // Memory usage as reported by Container Station
+	TX        int32     // Network bytes sent counter
+	RX        int32     // Network bytes received counter
+	Read      int32     // Block device bytes read counter
+	Write     int32     // Block device bytes written counter
+	TXRate    float64   // Network bytes sent per second since the previous sample
+	RXRate    float64   // Network bytes received per second since the previous sample
+	ReadRate  float64   // Block device bytes read per second since the previous sample
+	WriteRate float64   // Block device bytes written per second since the previous sample
+	Err       error     // Only set on the last value, when the channel is closed because polling kept failing
+}
+
+// maxPollFailures is the number of consecutive failed polls after which a stream gives up
+var maxPollFailures = 5
+
+// StreamContainerStats samples the resource usage of a container every interval until ctx is cancelled.
+// Container Station has no stats endpoint, so the container list is polled and rates are computed from
+// the counters of consecutive samples. The channel is closed when ctx is done or the container disappears.
+// A failed poll is retried on the next tick, after maxPollFailures failures in a row a last value with
+// only Time and Err set is sent and the channel is closed
+func (c *Client) StreamContainerStats(ctx context.Context, containerID string, interval time.Duration) (<-chan ContainerStats, error) {
+	if interval <= 0 {
+		return nil, errors.New("stats interval must be positive")
+	}
+
+	first, err := c.containerStatsSample(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(chan ContainerStats)
+	go func() {
+		defer close(stats)
+
+		previous := *first
+		failures := 0
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		select {
+		case <-ctx.Done():
+			return
+		case stats <- previous:
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			next, err := c.containerStatsSample(containerID)
+			if err != nil {
+				if errors.Is(err, errContainerNotFound) {
+					return
+				}
+				failures++
+				if failures < maxPollFailures {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+				case stats <- ContainerStats{Time: time.Now(), Err: fmt.Errorf("stats polling failed %d times: %w", failures, err)}:
+				}
+				return
+			}
+			failures = 0
+
+			elapsed := next.Time.Sub(previous.Time).Seconds()
+			next.TXRate = counterRate(previous.TX, next.TX, elapsed)
+			next.RXRate = counterRate(previous.RX, next.RX, elapsed)
+			next.ReadRate = counterRate(previous.Read, next.Read, elapsed)
+			next.WriteRate = counterRate(previous.Write, next.Write, elapsed)
+			previous = *next
+
+			select {
+			case <-ctx.Done():
+				return
+			case stats <- previous:
+			}
+		}
+	}()
+
+	return stats, nil
+}
+
+var errContainerNotFound = errors.New("container not found")
+
+// containerStatsSample reads the current counters of a container from the container list
+func (c *Client) containerStatsSample(containerID string) (*ContainerStats, error) {
+	containers, err := c.GetContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, container := range containers {
+		if container.ID == containerID {
+			return &ContainerStats{
+				Time:   time.Now(),
+				CPU:    container.CPU,
+				Memory: container.Memory,
+				TX:     container.TX,
+				RX:     container.RX,
+				Read:   container.Read,
+				Write:  container.Write,
+			}, nil
+		}
+	}
+	return nil, errContainerNotFound
+}
+
+// counterRate returns the per second increase of a counter, a counter reset yields zero
+func counterRate(previous, current int32, seconds float64) float64 {
+	if seconds <= 0 || current < previous {
+		return 0
+	}
+	return (float64(current) - float64(previous)) / seconds
+}
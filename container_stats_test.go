@@ -0,0 +1,83 @@
+package qnap
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamContainerStats(t *testing.T) {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "c1", Name: "web", Type: "docker", Status: "running"})
+	client := station.client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stats, err := client.StreamContainerStats(ctx, "c1", 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("StreamContainerStats: %v", err)
+	}
+
+	next := func() (ContainerStats, bool) {
+		t.Helper()
+		select {
+		case sample, ok := <-stats:
+			return sample, ok
+		case <-time.After(5 * time.Second):
+			t.Fatal("no sample")
+			return ContainerStats{}, false
+		}
+	}
+
+	if sample, ok := next(); !ok || sample.Err != nil {
+		t.Fatalf("first sample %+v, %v", sample, ok)
+	}
+
+	// Fewer failures than maxPollFailures in a row are skipped
+	station.fail(maxPollFailures - 1)
+	for range 2 {
+		if sample, ok := next(); !ok || sample.Err != nil {
+			t.Fatalf("sample after a short outage %+v, %v", sample, ok)
+		}
+	}
+
+	// Polling that keeps failing ends the stream with the error
+	station.fail(maxPollFailures)
+	var last ContainerStats
+	for {
+		sample, ok := next()
+		if !ok {
+			break
+		}
+		last = sample
+	}
+	if last.Err == nil || !strings.Contains(last.Err.Error(), "stats polling failed 5 times: status: 500") {
+		t.Errorf("last sample error %v", last.Err)
+	}
+}
+
+func TestStreamContainerStatsContainerRemoved(t *testing.T) {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "c1", Name: "web", Type: "docker", Status: "running"})
+	client := station.client()
+
+	stats, err := client.StreamContainerStats(context.Background(), "c1", 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("StreamContainerStats: %v", err)
+	}
+	<-stats
+
+	station.mu.Lock()
+	station.containers = nil
+	station.mu.Unlock()
+
+	select {
+	case sample, ok := <-stats:
+		if ok {
+			t.Errorf("sample %+v after the container was removed", sample)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not closed")
+	}
+}
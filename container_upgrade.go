@@ -0,0 +1,99 @@
+package qnap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UpgradeOptions describes the image a container is upgraded to.
+// KeepVolumes defaults to false, which recreates the container without any volume mounts: a database
+// upgraded without it starts with empty storage. Set it unless the new image must not see the old data
+type UpgradeOptions struct {
+	Image       string // The new image reference, for example "nginx:1.27"
+	Pull        bool   // Pull the image before recreating the container
+	KeepVolumes bool   // Keep the volume mounts of the current container, they are all dropped when false
+}
+
+// upgradeStartTimeout is how long an upgraded container may take to reach running before it is rolled back
+var upgradeStartTimeout = 60 * time.Second
+
+// UpgradeContainer recreates a container from its current specification with a new image.
+// When the upgraded container does not reach running it is recreated again with the previous image,
+// and a container that was running before must reach running again for the rollback to succeed
+func (c *Client) UpgradeContainer(ctx context.Context, containerID string, containerType string, options UpgradeOptions, authToken *string) (*ContainerInfo, error) {
+	if options.Image == "" {
+		return nil, errors.New("upgrade image must not be empty")
+	}
+
+	containerInfo, err := c.InspectContainer(containerID, containerType, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	previousSpec := containerInfo.ToSpec()
+	previousSpec.Operation = "recreate"
+	// The rollback restores what Container Station accepted before, it must not be blocked by validation
+	previousSpec.SkipValidation = true
+
+	upgradeSpec := containerInfo.ToSpec()
+	upgradeSpec.Operation = "recreate"
+	upgradeSpec.Image = options.Image
+	upgradeSpec.Pull = options.Pull
+	if !options.KeepVolumes {
+		upgradeSpec.Volumes = nil
+	}
+
+	// Check the new spec while the container still runs, so an invalid image does not cause a stop and rollback
+	err = upgradeSpec.Validate()
+	if err != nil {
+		return nil, err
+	}
+	err = c.checkPortConflicts(upgradeSpec)
+	if err != nil {
+		return nil, err
+	}
+	upgradeSpec.SkipValidation = true
+	upgradeSpec.SkipPortConflictCheck = true
+
+	if containerInfo.Data.Status == ContainerStatusRunning {
+		_, err = c.StopContainer(containerID, containerType, authToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	upgradedInfo, err := c.createContainer(ctx, upgradeSpec, authToken)
+	if err == nil {
+		err = c.ConnectAdditionalNetworks(upgradedInfo.Data.ID, upgradedInfo.Data.Type, containerInfo, authToken)
+	}
+	if err == nil {
+		waitCtx, cancel := context.WithTimeout(ctx, upgradeStartTimeout)
+		upgradedInfo, err = c.WaitContainer(waitCtx, upgradedInfo.Data.ID, ConditionRunning)
+		cancel()
+		if err == nil {
+			return upgradedInfo, nil
+		}
+	}
+
+	// The rollback still runs when ctx is cancelled, the container must not be left stopped
+	rollbackCtx := context.WithoutCancel(ctx)
+	rollbackInfo, rollbackErr := c.createContainer(rollbackCtx, previousSpec, authToken)
+	if rollbackErr == nil {
+		rollbackErr = c.ConnectAdditionalNetworks(rollbackInfo.Data.ID, rollbackInfo.Data.Type, containerInfo, authToken)
+	}
+	if rollbackErr == nil && containerInfo.Data.Status == ContainerStatusRunning {
+		waitCtx, cancel := context.WithTimeout(rollbackCtx, upgradeStartTimeout)
+		_, rollbackErr = c.WaitContainer(waitCtx, rollbackInfo.Data.ID, ConditionRunning)
+		cancel()
+	}
+	if rollbackErr != nil {
+		return nil, fmt.Errorf("upgrade to %s failed: %w, rollback to %s failed: %v", options.Image, err, previousSpec.Image, rollbackErr)
+	}
+	return nil, fmt.Errorf("upgrade to %s failed, rolled back to %s: %w", options.Image, previousSpec.Image, err)
+}
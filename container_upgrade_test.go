@@ -0,0 +1,96 @@
+package qnap
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newUpgradeStation returns a fake station with a running database container on two networks
+func newUpgradeStation(t *testing.T) (*fakeStation, *Client, string) {
+	t.Helper()
+	timeout, poll := upgradeStartTimeout, waitPollInitial
+	t.Cleanup(func() { upgradeStartTimeout, waitPollInitial = timeout, poll })
+	upgradeStartTimeout = 100 * time.Millisecond
+	waitPollInitial = 5 * time.Millisecond
+
+	station := newFakeStation(t)
+	client := station.client()
+	created, err := client.CreateContainer(NewContainerSpec{
+		Type: "docker", Name: "db", Image: "postgres:16", Network: "bridge",
+		Volumes: []Volumes{{Type: "volume", Name: "db-data", Destination: "/var/lib/postgresql/data", Permission: "rw"}},
+	}, &client.Token)
+	if err != nil {
+		t.Fatalf("CreateContainer: %v", err)
+	}
+	if _, err := client.ConnectContainerNetwork(created.Data.ID, "docker", "backend", ConnectOptions{IPAddress: "10.20.0.5"}, &client.Token); err != nil {
+		t.Fatalf("ConnectContainerNetwork: %v", err)
+	}
+	return station, client, created.Data.ID
+}
+
+func TestUpgradeContainer(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepVolumes bool
+		volumes     int
+	}{
+		{name: "volumes kept", keepVolumes: true, volumes: 1},
+		{name: "volumes dropped by default", volumes: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, client, id := newUpgradeStation(t)
+
+			upgraded, err := client.UpgradeContainer(context.Background(), id, "docker",
+				UpgradeOptions{Image: "postgres:17", KeepVolumes: test.keepVolumes}, &client.Token)
+			if err != nil {
+				t.Fatalf("UpgradeContainer: %v", err)
+			}
+			if upgraded.Data.ID == id || upgraded.Data.Image != "postgres:17" || upgraded.Data.Status != ContainerStatusRunning {
+				t.Errorf("upgraded container %s, %s, %s", upgraded.Data.ID, upgraded.Data.Image, upgraded.Data.Status)
+			}
+			if len(upgraded.Data.Volumes) != test.volumes {
+				t.Errorf("%d volumes, want %d", len(upgraded.Data.Volumes), test.volumes)
+			}
+			if networks := upgraded.Data.Networks; len(networks) != 2 || networks[1].Name != "backend" || networks[1].IPAddress != "10.20.0.5" {
+				t.Errorf("networks %+v, want bridge and backend at 10.20.0.5", networks)
+			}
+		})
+	}
+}
+
+func TestUpgradeContainerRollback(t *testing.T) {
+	station, client, id := newUpgradeStation(t)
+	station.imageState["postgres:17"] = "exited"
+
+	_, err := client.UpgradeContainer(context.Background(), id, "docker", UpgradeOptions{Image: "postgres:17", KeepVolumes: true}, &client.Token)
+	if err == nil || !strings.Contains(err.Error(), "upgrade to postgres:17 failed, rolled back to postgres:16") {
+		t.Fatalf("error %v, want the rollback reported", err)
+	}
+
+	container, err := client.FindContainer("db")
+	if err != nil {
+		t.Fatalf("FindContainer: %v", err)
+	}
+	rolledBack, err := client.InspectContainer(container.ID, "docker", &client.Token)
+	if err != nil {
+		t.Fatalf("InspectContainer: %v", err)
+	}
+	if rolledBack.Data.Image != "postgres:16" || rolledBack.Data.Status != ContainerStatusRunning || len(rolledBack.Data.Networks) != 2 {
+		t.Errorf("rolled back container %s, %s, %d networks", rolledBack.Data.Image, rolledBack.Data.Status, len(rolledBack.Data.Networks))
+	}
+}
+
+func TestUpgradeContainerRollbackNotRunning(t *testing.T) {
+	station, client, id := newUpgradeStation(t)
+	station.imageState["postgres:17"] = "exited"
+	station.imageState["postgres:16"] = "exited"
+
+	_, err := client.UpgradeContainer(context.Background(), id, "docker", UpgradeOptions{Image: "postgres:17", KeepVolumes: true}, &client.Token)
+	if err == nil || !strings.Contains(err.Error(), "rollback to postgres:16 failed: waiting for container") {
+		t.Errorf("error %v, want the rolled back container reported as not running", err)
+	}
+}
@@ -0,0 +1,176 @@
+package qnap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Condition is a container state WaitContainer waits for
+type Condition struct {
+	state    string
+	exitCode int32
+	anyCode  bool
+	port     int32
+}
+
+var (
+	ConditionRunning = Condition{state: "running"}               // The container is running
+	ConditionStopped = Condition{state: "stopped"}               // The container is not running, whatever its exit code
+	ConditionHealthy = Condition{state: "healthy"}               // The healthcheck of the container reports healthy, unhealthy fails the wait
+	ConditionRemoved = Condition{state: "removed"}               // The container no longer exists
+	ConditionExited  = Condition{state: "exited", anyCode: true} // The container exited, whatever its exit code
+)
+
+// ConditionExitedWith waits for the container to exit with the given exit code
+func ConditionExitedWith(exitCode int32) Condition {
+	return Condition{state: "exited", exitCode: exitCode}
+}
+
+// ConditionPortListening waits for the host port published for containerPort to accept TCP connections
+func ConditionPortListening(containerPort int32) Condition {
+	return Condition{state: "port-listening", port: containerPort}
+}
+
+// String describes the condition
+func (condition Condition) String() string {
+	switch {
+	case condition.state == "exited" && !condition.anyCode:
+		return "exited with code " + strconv.Itoa(int(condition.exitCode))
+	case condition.state == "port-listening":
+		return "listening on container port " + strconv.Itoa(int(condition.port))
+	}
+	return condition.state
+}
+
+// waitPollInitial and waitPollMax bound the delay between two polls of WaitContainer
+var (
+	waitPollInitial = 500 * time.Millisecond
+	waitPollMax     = 5 * time.Second
+)
+
+// WaitContainer polls a container until it meets the condition and returns its last inspected state,
+// which is nil for ConditionRemoved. Set a deadline on ctx to bound the wait, the error then reports the last state seen
+func (c *Client) WaitContainer(ctx context.Context, containerID string, condition Condition) (*ContainerInfo, error) {
+	delay := waitPollInitial
+	lastState := "unknown"
+
+	for {
+		containerInfo, state, done, err := c.checkContainerCondition(containerID, condition)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return containerInfo, nil
+		}
+		lastState = state
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for container %s to be %s: %w, last state: %s", containerID, condition, ctx.Err(), lastState)
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > waitPollMax {
+			delay = waitPollMax
+		}
+	}
+}
+
+// checkContainerCondition inspects the container once and reports its state and whether the condition is met.
+// An error is returned when the condition can no longer be met
+func (c *Client) checkContainerCondition(containerID string, condition Condition) (*ContainerInfo, string, bool, error) {
+	containers, err := c.GetContainers()
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	var container *Container
+	for i := range containers {
+		if containers[i].ID == containerID {
+			container = &containers[i]
+			break
+		}
+	}
+
+	if container == nil {
+		if condition.state == "removed" {
+			return nil, "removed", true, nil
+		}
+		return nil, "", false, errors.New("container " + containerID + " was removed while waiting for " + condition.String())
+	}
+	if condition.state == "removed" {
+		return nil, container.Status, false, nil
+	}
+
+	containerInfo, err := c.InspectContainer(container.ID, container.Type, &c.Token)
+	if err != nil {
+		return nil, "", false, err
+	}
+	state := containerInfo.Data.Status
+	dockerStatus := containerInfo.Data.DockerStatus
+
+	switch condition.state {
+	case "running":
+		return containerInfo, state, state == ContainerStatusRunning, nil
+	case "stopped":
+		return containerInfo, state, state != ContainerStatusRunning && state != "paused" && !dockerStatus.Restarting, nil
+	case "exited":
+		state = fmt.Sprintf("%s, exit code %d", state, dockerStatus.ExitCode)
+		if dockerStatus.Running || dockerStatus.Restarting || containerInfo.Data.Status == ContainerStatusRunning {
+			return containerInfo, state, false, nil
+		}
+		if !condition.anyCode && dockerStatus.ExitCode != condition.exitCode {
+			return nil, "", false, fmt.Errorf("container %s exited with code %d instead of %d", containerID, dockerStatus.ExitCode, condition.exitCode)
+		}
+		return containerInfo, state, true, nil
+	case "healthy":
+		health := dockerStatus.Health
+		if health == "" || health == "none" {
+			return nil, "", false, errors.New("container " + containerID + " has no healthcheck")
+		}
+		if health == "unhealthy" {
+			return nil, "", false, errors.New("container " + containerID + " is unhealthy")
+		}
+		return containerInfo, state + ", " + health, health == "healthy", nil
+	case "port-listening":
+		address, err := c.publishedAddress(containerInfo, condition.port)
+		if err != nil {
+			return nil, "", false, err
+		}
+		conn, err := net.DialTimeout("tcp", address, 2*time.Second)
+		if err != nil {
+			return containerInfo, state + ", " + address + " not listening", false, nil
+		}
+		conn.Close()
+		return containerInfo, state, true, nil
+	}
+	return nil, "", false, errors.New("unknown container condition " + condition.state)
+}
+
+// publishedAddress returns the host address the container port is published on
+func (c *Client) publishedAddress(containerInfo *ContainerInfo, containerPort int32) (string, error) {
+	hostURL, err := url.Parse(c.HostURL)
+	if err != nil {
+		return "", err
+	}
+
+	for _, portBinding := range containerInfo.Data.PortBindings {
+		if portBinding.Container != containerPort || portBinding.Host == 0 ||
+			(portBinding.Protocol != "" && strings.ToLower(portBinding.Protocol) != "tcp") {
+			continue
+		}
+		host := hostURL.Hostname()
+		if ip := net.ParseIP(portBinding.HostIP); ip != nil && !ip.IsUnspecified() && !ip.IsLoopback() {
+			host = portBinding.HostIP
+		}
+		return net.JoinHostPort(host, strconv.Itoa(int(portBinding.Host))), nil
+	}
+	return "", fmt.Errorf("container port %d is not published on a host TCP port", containerPort)
+}
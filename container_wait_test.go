@@ -0,0 +1,67 @@
+package qnap
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitContainerHealthy(t *testing.T) {
+	previous := waitPollInitial
+	waitPollInitial = time.Millisecond
+	t.Cleanup(func() { waitPollInitial = previous })
+
+	tests := []struct {
+		name    string
+		health  []string // Health reported by consecutive inspects, the last one repeats
+		timeout time.Duration
+		problem string
+	}{
+		{name: "becomes healthy", health: []string{"starting", "starting", "healthy"}, timeout: 5 * time.Second},
+		{name: "unhealthy", health: []string{"starting", "unhealthy"}, timeout: 5 * time.Second, problem: "is unhealthy"},
+		{name: "no healthcheck", health: []string{""}, timeout: 5 * time.Second, problem: "has no healthcheck"},
+		{name: "timeout", health: []string{"starting"}, timeout: 50 * time.Millisecond, problem: "last state: running, starting"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			station := newFakeStation(t)
+			station.add(&fakeContainer{ID: "docker-1", Name: "web", Type: "docker", Status: ContainerStatusRunning})
+			client := station.client()
+
+			setHealth := func(health string) {
+				station.setSpec("docker-1", "dockerStatus", map[string]any{"running": true, "health": health})
+			}
+			setHealth(test.health[0])
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				for _, health := range test.health[1:] {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(5 * time.Millisecond):
+					}
+					setHealth(health)
+				}
+			}()
+
+			info, err := client.WaitContainerHealthy("docker-1", "docker", test.timeout, &client.Token)
+			if test.problem == "" {
+				if err != nil || info.Data.DockerStatus.Health != "healthy" {
+					t.Fatalf("WaitContainerHealthy = %v, %v", info, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Fatalf("error %v, want it to contain %q", err, test.problem)
+			}
+			if test.name == "timeout" && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("timeout error %v does not wrap context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
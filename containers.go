@@ -1,6 +1,7 @@
 package qnap
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,7 +27,8 @@ type ChangeContainer struct {
 }
 
 type ChangeContainerData struct {
-	Items []Item `json:"items"`
+	Items  []Item `json:"items"`
+	Signal string `json:"signal,omitempty"`
 }
 
 type Item struct {
@@ -63,72 +65,91 @@ func (c *Client) GetContainers() ([]Container, error) {
 
 // CreateContainer creates a new container
 func (c *Client) CreateContainer(container NewContainerSpec, authToken *string) (*ContainerInfo, error) {
-	containerName := container.Name
-	containerOperation := container.Operation
-	TaskStatusCompleted := "completed"
+	return c.createContainer(context.Background(), container, authToken)
+}
+
+// createContainer creates a new container, ctx bounds the creation task and the healthcheck wait
+func (c *Client) createContainer(ctx context.Context, container NewContainerSpec, authToken *string) (*ContainerInfo, error) {
+	if !container.SkipValidation {
+		err := container.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !container.SkipPortConflictCheck {
+		err := c.checkPortConflicts(container)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	rb, err := json.Marshal(container)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/container-station/api/v3/containers", c.HostURL), strings.NewReader(string(rb)))
+	containerID, containerType, err := c.submitContainer(ctx, rb, container.Name, container.Operation)
 	if err != nil {
 		return nil, err
 	}
 
-	containersBefore, err := c.GetContainerStationOverview()
+	newContainerInfo, err := c.InspectContainer(containerID, containerType, authToken)
 	if err != nil {
 		return nil, err
 	}
+	if container.WaitHealthy > 0 {
+		return c.waitContainerHealthy(ctx, containerID, container.WaitHealthy)
+	}
+	return newContainerInfo, nil
+}
+
+// submitContainer posts a container creation payload, waits for the task and returns the ID and type of the new container
+func (c *Client) submitContainer(ctx context.Context, rb []byte, containerName string, containerOperation string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/container-station/api/v3/containers", c.HostURL), strings.NewReader(string(rb)))
+	if err != nil {
+		return "", "", err
+	}
+
+	containersBefore, err := c.GetContainerStationOverview()
+	if err != nil {
+		return "", "", err
+	}
 
 	for _, containerBefore := range containersBefore.Data.Container {
 		if containerBefore.Name == containerName && containerOperation != "recreate" {
-			return nil, errors.New("cannot create container as a container with the same name already exists")
+			return "", "", errors.New("cannot create container as a container with the same name already exists")
 		}
 	}
 
 	body, _, err := c.doRequest(req, &c.Token)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
 	var response ContainerStationTaskResponse
 	err = json.Unmarshal(body, &response)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
-	for {
-		taskStatus, err := c.GetTaskStatus(response.Data.TaskID)
-		if err != nil {
-			return nil, err
-		}
-
-		if taskStatus == TaskStatusCompleted {
-			break
-		}
-
-		time.Sleep(2 * time.Second)
+	err = c.waitForTask(ctx, response.Data.TaskID)
+	if err != nil {
+		return "", "", err
 	}
 
 	containersAfter, err := c.GetContainerStationOverview()
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
-	var newContainerInfo *ContainerInfo
 	for _, containerAfter := range containersAfter.Data.Container {
 		if containerAfter.Name == containerName {
-			newContainerInfo, err = c.InspectContainer(containerAfter.ID, containerAfter.Type, authToken)
-			if err != nil {
-				return nil, err
-			}
-			return newContainerInfo, nil
+			return containerAfter.ID, containerAfter.Type, nil
 		}
 	}
 
-	return nil, errors.New("container is not found after creation. Possible options: QNAP container station needs more time or the container creation failed silently")
+	return "", "", errors.New("container is not found after creation. Possible options: QNAP container station needs more time or the container creation failed silently")
 }
 
 // InspectContainer returns specific container specifications
@@ -153,6 +174,76 @@ func (c *Client) InspectContainer(containerID string, containerType string, auth
 	return &containerData, nil
 }
 
+// UpdateContainerResources changes the resource limits of a container without recreating it
+// and returns the container information once the change is confirmed.
+// Limits left nil are sent with their current values, so they are not reset to unlimited
+func (c *Client) UpdateContainerResources(containerID string, containerType string, update ResourceUpdate, authToken *string) (*ContainerInfo, error) {
+	current, err := c.InspectContainer(containerID, containerType, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	request := update
+	if request.CPULimit == nil {
+		request.CPULimit = &current.Data.CPULimit
+	}
+	if request.MemLimit == nil {
+		request.MemLimit = &current.Data.MemLimit
+	}
+	if request.MemReservation == nil {
+		request.MemReservation = &current.Data.MemReservation
+	}
+
+	rb, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/container-station/api/v3/containers/%s/resource?id=%s",
+		c.HostURL, containerType, containerID), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+
+	body, _, err := c.doRequest(req, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.waitForResponseTask(context.Background(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	containerInfo, err := c.InspectContainer(containerID, containerType, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []string
+	if update.CPULimit != nil && containerInfo.Data.CPULimit != *update.CPULimit {
+		mismatches = append(mismatches, "cpuLimit")
+	}
+	if update.MemLimit != nil && containerInfo.Data.MemLimit != *update.MemLimit {
+		mismatches = append(mismatches, "memLimit")
+	}
+	if update.MemReservation != nil && containerInfo.Data.MemReservation != *update.MemReservation {
+		mismatches = append(mismatches, "memReservation")
+	}
+	if update.Cpupin != nil && (containerInfo.Data.Cpupin.CPUIDs != update.Cpupin.CPUIDs || containerInfo.Data.Cpupin.Type != update.Cpupin.Type) {
+		mismatches = append(mismatches, "cpupin")
+	}
+	if update.RestartPolicy != nil && (containerInfo.Data.RestartPolicy.Name != update.RestartPolicy.Name ||
+		containerInfo.Data.RestartPolicy.MaximumRetryCount != update.RestartPolicy.MaximumRetryCount) {
+		mismatches = append(mismatches, "restartPolicy")
+	}
+	if len(mismatches) > 0 {
+		return nil, errors.New("container resource update failed to apply " + strings.Join(mismatches, ", "))
+	}
+
+	return containerInfo, nil
+}
+
 // DeleteContainer deletes a container
 func (c *Client) DeleteContainer(containerID string, containerType string, containerVolumeRemove bool, authToken *string) (bool, error) {
 	return c.ChangeContainerState(containerID, containerType, containerVolumeRemove, "delete", authToken)
@@ -163,112 +254,219 @@ func (c *Client) StartContainer(containerID string, containerType string, authTo
 	return c.ChangeContainerState(containerID, containerType, false, "start", authToken)
 }
 
+// StartContainerAndWaitHealthy starts a container and waits up to timeout for its healthcheck to report healthy
+func (c *Client) StartContainerAndWaitHealthy(containerID string, containerType string, timeout time.Duration, authToken *string) (bool, error) {
+	_, err := c.StartContainer(containerID, containerType, authToken)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = c.WaitContainerHealthy(containerID, containerType, timeout, authToken)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WaitContainerHealthy waits up to timeout for the healthcheck of the container to report healthy.
+// containerType and authToken are not used: like WaitContainer, it finds the container type in the
+// container list and polls with the session of the client
+func (c *Client) WaitContainerHealthy(containerID string, containerType string, timeout time.Duration, authToken *string) (*ContainerInfo, error) {
+	return c.waitContainerHealthy(context.Background(), containerID, timeout)
+}
+
+func (c *Client) waitContainerHealthy(ctx context.Context, containerID string, timeout time.Duration) (*ContainerInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return c.WaitContainer(ctx, containerID, ConditionHealthy)
+}
+
 // StopContainer stops a container
 func (c *Client) StopContainer(containerID string, containerType string, authToken *string) (bool, error) {
 	return c.ChangeContainerState(containerID, containerType, false, "stop", authToken)
 }
 
-// ChangeContainerState changes the state of a container - used by start,stop and delete functions
+// RestartContainer restarts a container
+func (c *Client) RestartContainer(containerID string, containerType string, authToken *string) (bool, error) {
+	return c.ChangeContainerState(containerID, containerType, false, "restart", authToken)
+}
+
+// PauseContainer pauses all processes of a container
+func (c *Client) PauseContainer(containerID string, containerType string, authToken *string) (bool, error) {
+	return c.ChangeContainerState(containerID, containerType, false, "pause", authToken)
+}
+
+// ResumeContainer resumes a paused container
+func (c *Client) ResumeContainer(containerID string, containerType string, authToken *string) (bool, error) {
+	return c.ChangeContainerState(containerID, containerType, false, "resume", authToken)
+}
+
+// KillContainer sends a signal to the main process of a container, SIGKILL when signal is empty.
+// The container is expected to be stopped afterwards only for SIGKILL, other signals can be handled by the process
+func (c *Client) KillContainer(containerID string, containerType string, signal string, authToken *string) (bool, error) {
+	return c.changeContainerState(containerID, containerType, false, "kill", signal, authToken)
+}
+
+// ChangeContainerState changes the state of a container - used by start, stop, restart, pause, resume, kill and delete functions
 func (c *Client) ChangeContainerState(containerID string, containerType string, containerVolumeRemove bool, operation string, authToken *string) (bool, error) {
+	return c.changeContainerState(containerID, containerType, containerVolumeRemove, operation, "", authToken)
+}
+
+func (c *Client) changeContainerState(containerID string, containerType string, containerVolumeRemove bool, operation string, signal string, authToken *string) (bool, error) {
+	items := []Item{{CID: containerID, CType: containerType}}
+
+	statuses, err := c.changeContainersState(items, containerVolumeRemove, operation, signal, authToken)
+	if err != nil {
+		return false, err
+	}
+
+	if operation == "delete" {
+		return true, nil
+	}
+
+	status, found := statuses[containerID]
+	if !found {
+		return false, errors.New("container operation " + operation + " failed to complete, container not found..")
+	}
+	if expected := expectedContainerStatus(operation, signal); expected != "" && status != expected {
+		return false, errors.New("container operation " + operation + " failed to complete")
+	}
+	return true, nil
+}
+
+// StartContainers starts several containers using a single task
+func (c *Client) StartContainers(items []Item, authToken *string) (map[string]bool, error) {
+	return c.ChangeContainersState(items, false, "start", authToken)
+}
+
+// StopContainers stops several containers using a single task
+func (c *Client) StopContainers(items []Item, authToken *string) (map[string]bool, error) {
+	return c.ChangeContainersState(items, false, "stop", authToken)
+}
+
+// DeleteContainers deletes several containers using a single task
+func (c *Client) DeleteContainers(items []Item, containerVolumeRemove bool, authToken *string) (map[string]bool, error) {
+	return c.ChangeContainersState(items, containerVolumeRemove, "delete", authToken)
+}
+
+// ChangeContainersState changes the state of several containers in one request and returns,
+// per container ID, whether the container reached the expected state after the task completed
+func (c *Client) ChangeContainersState(items []Item, containerVolumeRemove bool, operation string, authToken *string) (map[string]bool, error) {
+	statuses, err := c.changeContainersState(items, containerVolumeRemove, operation, "", authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]bool, len(items))
+	for _, item := range items {
+		status, found := statuses[item.CID]
+		if operation == "delete" {
+			results[item.CID] = !found
+		} else {
+			expected := expectedContainerStatus(operation, "")
+			results[item.CID] = found && (expected == "" || status == expected)
+		}
+	}
+	return results, nil
+}
+
+// containerOperationStatus maps a container operation to the status expected once it completed
+var containerOperationStatus = map[string]string{
+	"start":   ContainerStatusRunning,
+	"stop":    "stopped",
+	"restart": ContainerStatusRunning,
+	"pause":   "paused",
+	"resume":  ContainerStatusRunning,
+	"kill":    "stopped",
+}
+
+// expectedContainerStatus returns the status a container should have after the operation,
+// an empty string means any status is accepted as long as the container still exists
+func expectedContainerStatus(operation string, signal string) string {
+	if operation == "kill" {
+		switch strings.TrimPrefix(strings.ToUpper(signal), "SIG") {
+		case "", "KILL", "9":
+		default:
+			return ""
+		}
+	}
+	return containerOperationStatus[operation]
+}
+
+// changeContainersState submits the operation for all items, waits for the task and
+// returns the status of each requested container still present in the overview
+func (c *Client) changeContainersState(items []Item, containerVolumeRemove bool, operation string, signal string, authToken *string) (map[string]string, error) {
 	var httpOperation string
 	var rb []byte
 	var err error
 	var url string
 
-	if operation == "start" || operation == "stop" {
+	if _, ok := containerOperationStatus[operation]; ok {
 		container := ChangeContainer{
 			Data: ChangeContainerData{
-				Items: []Item{
-					{
-						CID:   containerID,
-						CType: containerType,
-					},
-				},
+				Items:  items,
+				Signal: signal,
 			},
 		}
 		rb, err = json.Marshal(container)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		httpOperation = "PUT"
 		url = fmt.Sprintf("%s/container-station/api/v3/containers/%s", c.HostURL, operation)
 	} else if operation == "delete" {
 		container := RemoveContainer{
 			Data: RemoveContainerData{
-				Items: []Item{
-					{
-						CID:   containerID,
-						CType: containerType,
-					},
-				},
+				Items:         items,
 				RemoveVolumes: containerVolumeRemove,
 			},
 		}
 		rb, err = json.Marshal(container)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		httpOperation = "DELETE"
 		url = fmt.Sprintf("%s/container-station/api/v3/containers", c.HostURL)
 	} else {
-		return false, errors.New("container operation " + operation + " not supported")
+		return nil, errors.New("container operation " + operation + " not supported")
 	}
 
 	req, err := http.NewRequest(httpOperation, url, strings.NewReader(string(rb)))
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	body, _, err := c.doRequest(req, authToken)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	var response ContainerStationTaskResponse
 	err = json.Unmarshal(body, &response)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	for {
-		taskStatus, err := c.GetTaskStatus(response.Data.TaskID)
-		if err != nil {
-			return false, err
-		}
-
-		if taskStatus == TaskStatusCompleted {
-			break
-		}
-
-		time.Sleep(2 * time.Second)
+	err = c.waitForTask(context.Background(), response.Data.TaskID)
+	if err != nil {
+		return nil, err
 	}
 
 	containersAfter, err := c.GetContainerStationOverview()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
+	requested := make(map[string]bool, len(items))
+	for _, item := range items {
+		requested[item.CID] = true
+	}
+
+	statuses := make(map[string]string, len(items))
 	for _, containerAfterItem := range containersAfter.Data.Container {
-		if containerAfterItem.ID == containerID {
-			switch operation {
-			case "start":
-				if containerAfterItem.Status == "running" {
-					return true, nil
-				} else {
-					return false, errors.New("container operation " + operation + " failed to complete")
-				}
-			case "stop":
-				if containerAfterItem.Status == "stopped" {
-					return true, nil
-				} else {
-					return false, errors.New("container operation " + operation + " failed to complete")
-				}
-			}
+		if requested[containerAfterItem.ID] {
+			statuses[containerAfterItem.ID] = containerAfterItem.Status
 		}
 	}
-	if operation == "delete" {
-		return true, nil
-	} else {
-		return false, errors.New("container operation " + operation + " failed to complete, container not found..")
-	}
+	return statuses, nil
 }
@@ -0,0 +1,50 @@
+package qnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpdateContainerResources(t *testing.T) {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "c1", Name: "db", Type: "docker", Status: "running",
+		Spec: map[string]any{"cpuLimit": 50, "memLimit": 1024, "memReservation": 256}})
+	client := station.client()
+
+	memLimit := int32(512)
+	containerInfo, err := client.UpdateContainerResources("c1", "docker", ResourceUpdate{MemLimit: &memLimit}, &client.Token)
+	if err != nil {
+		t.Fatalf("UpdateContainerResources: %v", err)
+	}
+
+	// The limits left nil keep their values, the fake removes limits missing from the request
+	data := containerInfo.Data
+	if data.CPULimit != 50 || data.MemLimit != 512 || data.MemReservation != 256 {
+		t.Errorf("limits %d, %d, %d, want 50, 512, 256", data.CPULimit, data.MemLimit, data.MemReservation)
+	}
+
+	noLimit := int32(0)
+	containerInfo, err = client.UpdateContainerResources("c1", "docker", ResourceUpdate{CPULimit: &noLimit}, &client.Token)
+	if err != nil {
+		t.Fatalf("UpdateContainerResources: %v", err)
+	}
+	data = containerInfo.Data
+	if data.CPULimit != 0 || data.MemLimit != 512 || data.MemReservation != 256 {
+		t.Errorf("limits %d, %d, %d, want 0, 512, 256", data.CPULimit, data.MemLimit, data.MemReservation)
+	}
+}
+
+func TestUpdateContainerResourcesNotApplied(t *testing.T) {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "c1", Name: "db", Type: "docker", Status: "running",
+		Spec: map[string]any{"cpuLimit": 50, "memLimit": 1024, "memReservation": 256}})
+	// Inspect reports the memory limit under another key, as if the update was ignored
+	station.inspectKey["memLimit"] = "ignoredMemLimit"
+	client := station.client()
+
+	memLimit := int32(512)
+	_, err := client.UpdateContainerResources("c1", "docker", ResourceUpdate{MemLimit: &memLimit}, &client.Token)
+	if err == nil || !strings.HasSuffix(err.Error(), "failed to apply memLimit") {
+		t.Errorf("error %v, want the memory limit reported", err)
+	}
+}
@@ -0,0 +1,738 @@
+package qnap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mohamed-mfarag/qnap-client-lib/compose"
+)
+
+// dockerRunFlags maps the supported docker run flags to their canonical long name and whether they take a value
+var dockerRunFlags = map[string]struct {
+	name     string
+	hasValue bool
+}{
+	"-p": {"publish", true}, "--publish": {"publish", true},
+	"-v": {"volume", true}, "--volume": {"volume", true},
+	"-e": {"env", true}, "--env": {"env", true},
+	"--env-file": {"env-file", true},
+	"--name":     {"name", true},
+	"--restart":  {"restart", true},
+	"--network":  {"network", true}, "--net": {"network", true},
+	"--ip":         {"ip", true},
+	"--device":     {"device", true},
+	"--privileged": {"privileged", false},
+	"--cpus":       {"cpus", true},
+	"-m":           {"memory", true}, "--memory": {"memory", true},
+	"--memory-reservation": {"memory-reservation", true},
+	"--cpuset-cpus":        {"cpuset-cpus", true},
+	"-h":                   {"hostname", true}, "--hostname": {"hostname", true},
+	"--dns":        {"dns", true},
+	"--entrypoint": {"entrypoint", true},
+	"-t":           {"tty", false}, "--tty": {"tty", false},
+	"-i": {"interactive", false}, "--interactive": {"interactive", false},
+	"-d": {"detach", false}, "--detach": {"detach", false},
+	"-l": {"label", true}, "--label": {"label", true},
+	"--rm":           {"rm", false},
+	"--runtime":      {"runtime", true},
+	"--pull":         {"pull", true},
+	"--cap-add":      {"cap-add", true},
+	"--cap-drop":     {"cap-drop", true},
+	"--security-opt": {"security-opt", true},
+	"--ulimit":       {"ulimit", true},
+	"--shm-size":     {"shm-size", true},
+	"-u":             {"user", true}, "--user": {"user", true},
+	"-w": {"workdir", true}, "--workdir": {"workdir", true},
+	"--sysctl":       {"sysctl", true},
+	"--tmpfs":        {"tmpfs", true},
+	"--read-only":    {"read-only", false},
+	"--stop-signal":  {"stop-signal", true},
+	"--stop-timeout": {"stop-timeout", true},
+}
+
+// ParseDockerRun converts the arguments of a docker run command into a NewContainerSpec.
+// A leading "docker run" or "docker container run" is ignored, the arguments after the image become the command.
+// CPU limits are converted to hundredths of a CPU and memory sizes to MiB
+func ParseDockerRun(args []string) (NewContainerSpec, error) {
+	spec := NewContainerSpec{
+		Type:        "docker",
+		Network:     "bridge",
+		NetworkType: "default",
+	}
+
+	args = trimDockerRunPrefix(args)
+
+	var problems []error
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			// Everything after -- is the image and its command
+			if i+1 < len(args) {
+				spec.Image = args[i+1]
+				spec.Cmd = append([]string(nil), args[i+2:]...)
+			}
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			spec.Image = arg
+			spec.Cmd = append([]string(nil), args[i+1:]...)
+			break
+		}
+
+		flags, err := splitDockerRunFlag(arg)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		for _, flag := range flags {
+			value := flag.value
+			if flag.hasValue && !flag.inline {
+				if i+1 >= len(args) {
+					problems = append(problems, fmt.Errorf("flag %s needs a value", flag.raw))
+					continue
+				}
+				i++
+				value = args[i]
+			}
+			if err := applyDockerRunFlag(&spec, flag.name, value, flag.inline); err != nil {
+				problems = append(problems, fmt.Errorf("%s %s: %w", flag.raw, value, err))
+			}
+		}
+	}
+
+	if spec.Image == "" {
+		problems = append(problems, errors.New("image is missing"))
+	}
+	if len(problems) > 0 {
+		return NewContainerSpec{}, errors.Join(problems...)
+	}
+	return spec, nil
+}
+
+type dockerRunFlag struct {
+	raw      string
+	name     string
+	value    string
+	hasValue bool
+	inline   bool // The value was part of the argument, as in --name=web or -p80:80
+}
+
+// splitDockerRunFlag resolves one argument into flags, expanding grouped short flags such as -it
+func splitDockerRunFlag(arg string) ([]dockerRunFlag, error) {
+	if strings.HasPrefix(arg, "--") {
+		raw, value, inline := strings.Cut(arg, "=")
+		definition, ok := dockerRunFlags[raw]
+		if !ok {
+			return nil, fmt.Errorf("flag %s is not supported", raw)
+		}
+		return []dockerRunFlag{{raw: raw, name: definition.name, value: value, hasValue: definition.hasValue, inline: inline}}, nil
+	}
+
+	var flags []dockerRunFlag
+	shorts := arg[1:]
+	for j := 0; j < len(shorts); j++ {
+		raw := "-" + shorts[j:j+1]
+		definition, ok := dockerRunFlags[raw]
+		if !ok {
+			return nil, fmt.Errorf("flag %s is not supported", raw)
+		}
+		flag := dockerRunFlag{raw: raw, name: definition.name, hasValue: definition.hasValue}
+		if definition.hasValue && j+1 < len(shorts) {
+			flag.value = strings.TrimPrefix(shorts[j+1:], "=")
+			flag.inline = true
+			flags = append(flags, flag)
+			break
+		}
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
+func trimDockerRunPrefix(args []string) []string {
+	if len(args) > 0 && args[0] == "docker" {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "container" {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "run" {
+		args = args[1:]
+	}
+	return args
+}
+
+// applyDockerRunFlag sets the spec field of one flag, explicit is false for boolean flags given without a value
+func applyDockerRunFlag(spec *NewContainerSpec, name string, value string, explicit bool) error {
+	enabled := true
+	if explicit {
+		switch name {
+		case "privileged", "tty", "interactive", "detach", "rm", "read-only":
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			enabled = parsed
+		}
+	}
+
+	switch name {
+	case "publish":
+		bindings, err := parsePortMapping(value)
+		if err != nil {
+			return err
+		}
+		spec.PortBindings = append(spec.PortBindings, bindings...)
+	case "volume":
+		volume, err := parseVolumeMapping(value)
+		if err != nil {
+			return err
+		}
+		spec.Volumes = append(spec.Volumes, volume)
+	case "env":
+		key, envValue, ok := strings.Cut(value, "=")
+		if !ok {
+			envValue, ok = os.LookupEnv(key)
+			if !ok {
+				return nil
+			}
+		}
+		if spec.Env == nil {
+			spec.Env = map[string]string{}
+		}
+		spec.Env[key] = envValue
+	case "env-file":
+		env, err := readEnvFile(value)
+		if err != nil {
+			return err
+		}
+		if spec.Env == nil {
+			spec.Env = map[string]string{}
+		}
+		for key, envValue := range env {
+			spec.Env[key] = envValue
+		}
+	case "name":
+		spec.Name = value
+	case "restart":
+		policy, retries, hasRetries := strings.Cut(value, ":")
+		spec.RestartPolicy = RestartPolicy{Name: policy}
+		if hasRetries {
+			count, err := strconv.ParseInt(retries, 10, 32)
+			if err != nil {
+				return err
+			}
+			spec.RestartPolicy.MaximumRetryCount = int32(count)
+		}
+	case "network":
+		spec.Network = value
+	case "ip":
+		spec.IPAddress = value
+	case "device":
+		parts := strings.Split(value, ":")
+		device := Devices{Name: parts[0]}
+		if len(parts) == 3 {
+			device.Permission = parts[2]
+		} else if len(parts) == 2 && strings.Trim(parts[1], "rwm") == "" {
+			device.Permission = parts[1]
+		}
+		spec.Devices = append(spec.Devices, device)
+	case "privileged":
+		spec.Privileged = enabled
+	case "cpus":
+		cpus, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		spec.CPULimit = cpusToLimit(cpus)
+	case "memory":
+		memory, err := parseMemorySize(value)
+		if err != nil {
+			return err
+		}
+		spec.MemLimit = memory
+	case "memory-reservation":
+		memory, err := parseMemorySize(value)
+		if err != nil {
+			return err
+		}
+		spec.MemReservation = memory
+	case "cpuset-cpus":
+		spec.Cpupin.CPUIDs = value
+	case "hostname":
+		spec.Hostname = value
+	case "dns":
+		spec.DNS = append(spec.DNS, value)
+	case "entrypoint":
+		spec.Entrypoint = []string{value}
+	case "tty":
+		spec.Tty = enabled
+	case "interactive":
+		spec.OpenStdin = enabled
+	case "detach":
+		// Containers are always created detached
+	case "label":
+		key, labelValue, _ := strings.Cut(value, "=")
+		if spec.Labels == nil {
+			spec.Labels = map[string]string{}
+		}
+		spec.Labels[key] = labelValue
+	case "rm":
+		spec.AutoRemove = enabled
+	case "runtime":
+		spec.Runtime = value
+	case "pull":
+		switch value {
+		case "always":
+			spec.Pull = true
+		case "missing", "never":
+			spec.Pull = false
+		default:
+			return errors.New("pull policy must be always, missing or never")
+		}
+	case "cap-add":
+		spec.CapAdd = append(spec.CapAdd, value)
+	case "cap-drop":
+		spec.CapDrop = append(spec.CapDrop, value)
+	case "security-opt":
+		spec.SecurityOpt = append(spec.SecurityOpt, value)
+	case "ulimit":
+		ulimit, err := parseUlimit(value)
+		if err != nil {
+			return err
+		}
+		spec.Ulimits = append(spec.Ulimits, ulimit)
+	case "shm-size":
+		size, err := compose.ParseMemory(value)
+		if err != nil {
+			return err
+		}
+		spec.ShmSize = size
+	case "user":
+		spec.User = value
+	case "workdir":
+		spec.WorkingDir = value
+	case "sysctl":
+		key, sysctlValue, ok := strings.Cut(value, "=")
+		if !ok {
+			return errors.New("sysctl must be key=value")
+		}
+		if spec.Sysctls == nil {
+			spec.Sysctls = map[string]string{}
+		}
+		spec.Sysctls[key] = sysctlValue
+	case "tmpfs":
+		mountPath, options, _ := strings.Cut(value, ":")
+		if spec.Tmpfs == nil {
+			spec.Tmpfs = map[string]string{}
+		}
+		spec.Tmpfs[mountPath] = options
+	case "read-only":
+		spec.ReadonlyRootfs = enabled
+	case "stop-signal":
+		spec.StopSignal = value
+	case "stop-timeout":
+		timeout, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		spec.StopTimeout = int32(timeout)
+	}
+	return nil
+}
+
+// parseUlimit parses name=soft[:hard], the hard limit defaults to the soft limit
+func parseUlimit(value string) (Ulimit, error) {
+	name, limits, ok := strings.Cut(value, "=")
+	if !ok {
+		return Ulimit{}, errors.New("ulimit must be name=soft[:hard]")
+	}
+	soft, hard, hasHard := strings.Cut(limits, ":")
+
+	ulimit := Ulimit{Name: name}
+	var err error
+	ulimit.Soft, err = strconv.ParseInt(soft, 10, 64)
+	if err != nil {
+		return Ulimit{}, err
+	}
+	ulimit.Hard = ulimit.Soft
+	if hasHard {
+		ulimit.Hard, err = strconv.ParseInt(hard, 10, 64)
+		if err != nil {
+			return Ulimit{}, err
+		}
+	}
+	return ulimit, nil
+}
+
+// parsePortMapping parses [[hostIP:][hostPort]:]containerPort[/protocol], port ranges expand to one binding per port
+func parsePortMapping(value string) ([]PortBindings, error) {
+	mapping, protocol, _ := strings.Cut(value, "/")
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	var hostIP string
+	if strings.HasPrefix(mapping, "[") {
+		end := strings.Index(mapping, "]:")
+		if end < 0 {
+			return nil, errors.New("invalid IPv6 host address")
+		}
+		hostIP = mapping[1:end]
+		mapping = mapping[end+2:]
+	}
+
+	parts := strings.Split(mapping, ":")
+	var hostPorts, containerPorts string
+	switch {
+	case len(parts) == 1:
+		containerPorts = parts[0]
+	case len(parts) == 2:
+		hostPorts, containerPorts = parts[0], parts[1]
+	case len(parts) == 3 && hostIP == "":
+		hostIP, hostPorts, containerPorts = parts[0], parts[1], parts[2]
+	default:
+		return nil, errors.New("port mapping must be [[hostIP:][hostPort]:]containerPort[/protocol]")
+	}
+
+	containerFrom, containerTo, err := parsePortRange(containerPorts)
+	if err != nil {
+		return nil, err
+	}
+	hostFrom, hostTo := int32(0), int32(0)
+	if hostPorts != "" {
+		hostFrom, hostTo, err = parsePortRange(hostPorts)
+		if err != nil {
+			return nil, err
+		}
+		if hostTo-hostFrom != containerTo-containerFrom {
+			return nil, errors.New("host and container port ranges differ in size")
+		}
+	}
+
+	var bindings []PortBindings
+	for offset := int32(0); containerFrom+offset <= containerTo; offset++ {
+		binding := PortBindings{
+			Container: containerFrom + offset,
+			Protocol:  protocol,
+			HostIP:    hostIP,
+		}
+		if hostFrom != 0 {
+			binding.Host = hostFrom + offset
+		}
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
+
+func parsePortRange(value string) (int32, int32, error) {
+	first, last, isRange := strings.Cut(value, "-")
+	from, err := strconv.ParseInt(first, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	to := from
+	if isRange {
+		to, err = strconv.ParseInt(last, 10, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+		if to < from {
+			return 0, 0, errors.New("port range is descending")
+		}
+	}
+	return int32(from), int32(to), nil
+}
+
+// parseVolumeMapping parses [source:]destination[:options], an absolute source is a host path,
+// any other source a named volume and a lone destination a new anonymous volume
+func parseVolumeMapping(value string) (Volumes, error) {
+	parts := strings.Split(value, ":")
+	volume := Volumes{Permission: "rw"}
+
+	switch len(parts) {
+	case 1:
+		volume.Type = "new"
+		volume.Destination = parts[0]
+		return volume, nil
+	case 2:
+		if strings.HasPrefix(parts[1], "/") {
+			volume.Destination = parts[1]
+		} else {
+			// A lone destination with options
+			volume.Type = "new"
+			volume.Destination = parts[0]
+			return volume, applyVolumeOptions(&volume, parts[1])
+		}
+	case 3:
+		volume.Destination = parts[1]
+		if err := applyVolumeOptions(&volume, parts[2]); err != nil {
+			return volume, err
+		}
+	default:
+		return volume, errors.New("volume must be [source:]destination[:options]")
+	}
+
+	if strings.HasPrefix(parts[0], "/") {
+		volume.Type = "host"
+		volume.Source = parts[0]
+	} else {
+		volume.Type = "volume"
+		volume.Name = parts[0]
+	}
+	return volume, nil
+}
+
+func applyVolumeOptions(volume *Volumes, options string) error {
+	for _, option := range strings.Split(options, ",") {
+		switch option {
+		case "ro", "rw":
+			volume.Permission = option
+		case "z", "Z", "nocopy", "rprivate", "private", "rshared", "shared", "rslave", "slave":
+			// Not supported by Container Station, the mount still works without them
+		default:
+			return errors.New("volume option " + option + " is not supported")
+		}
+	}
+	return nil
+}
+
+// readEnvFile reads KEY=VALUE lines, blank lines and # comments are skipped and a lone KEY is read from the environment
+func readEnvFile(fileName string) (map[string]string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			value, ok = os.LookupEnv(key)
+			if !ok {
+				continue
+			}
+		}
+		env[key] = value
+	}
+	return env, scanner.Err()
+}
+
+// cpusToLimit converts a number of CPUs to the CPU limit unit, hundredths of a CPU
+func cpusToLimit(cpus float64) int32 {
+	return int32(math.Round(cpus * 100))
+}
+
+// limitToCPUs converts a CPU limit back to a number of CPUs
+func limitToCPUs(limit int32) float64 {
+	return float64(limit) / 100
+}
+
+// parseMemorySize converts a docker memory size such as 512m or 2g to MiB, a plain number is bytes
+func parseMemorySize(value string) (int32, error) {
+	size, err := compose.ParseMemory(value)
+	if err != nil {
+		return 0, err
+	}
+	return int32((size + 1<<20 - 1) / (1 << 20)), nil
+}
+
+// DockerRunArgs renders the spec as the arguments of an equivalent docker run command, starting with "docker run"
+func (spec *NewContainerSpec) DockerRunArgs() []string {
+	args := []string{"docker", "run", "-d"}
+
+	if spec.Name != "" {
+		args = append(args, "--name", spec.Name)
+	}
+	if spec.Hostname != "" {
+		args = append(args, "--hostname", spec.Hostname)
+	}
+	if spec.AutoRemove {
+		args = append(args, "--rm")
+	}
+	if spec.Tty {
+		args = append(args, "-t")
+	}
+	if spec.OpenStdin {
+		args = append(args, "-i")
+	}
+	if spec.Privileged {
+		args = append(args, "--privileged")
+	}
+	if spec.Pull {
+		args = append(args, "--pull", "always")
+	}
+	if spec.Runtime != "" {
+		args = append(args, "--runtime", spec.Runtime)
+	}
+	if spec.RestartPolicy.Name != "" {
+		restart := spec.RestartPolicy.Name
+		if spec.RestartPolicy.MaximumRetryCount > 0 {
+			restart += ":" + strconv.Itoa(int(spec.RestartPolicy.MaximumRetryCount))
+		}
+		args = append(args, "--restart", restart)
+	}
+	if spec.Network != "" && spec.Network != "bridge" {
+		args = append(args, "--network", spec.Network)
+	}
+	if spec.IPAddress != "" {
+		args = append(args, "--ip", spec.IPAddress)
+	}
+	for _, dns := range spec.DNS {
+		args = append(args, "--dns", dns)
+	}
+	for _, binding := range spec.PortBindings {
+		args = append(args, "-p", formatPortMapping(binding))
+	}
+	for _, volume := range spec.Volumes {
+		args = append(args, "-v", formatVolumeMapping(volume))
+	}
+	for _, device := range spec.Devices {
+		if device.Permission != "" {
+			args = append(args, "--device", device.Name+":"+device.Name+":"+device.Permission)
+		} else {
+			args = append(args, "--device", device.Name)
+		}
+	}
+	for _, key := range sortedKeys(spec.Env) {
+		args = append(args, "-e", key+"="+spec.Env[key])
+	}
+	for _, key := range sortedKeys(spec.Labels) {
+		args = append(args, "--label", key+"="+spec.Labels[key])
+	}
+	if spec.CPULimit > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(limitToCPUs(spec.CPULimit), 'f', -1, 64))
+	}
+	if spec.MemLimit > 0 {
+		args = append(args, "-m", strconv.Itoa(int(spec.MemLimit))+"m")
+	}
+	if spec.MemReservation > 0 {
+		args = append(args, "--memory-reservation", strconv.Itoa(int(spec.MemReservation))+"m")
+	}
+	if spec.Cpupin.CPUIDs != "" {
+		args = append(args, "--cpuset-cpus", spec.Cpupin.CPUIDs)
+	}
+	if spec.User != "" {
+		args = append(args, "--user", spec.User)
+	}
+	if spec.WorkingDir != "" {
+		args = append(args, "--workdir", spec.WorkingDir)
+	}
+	for _, capability := range spec.CapAdd {
+		args = append(args, "--cap-add", capability)
+	}
+	for _, capability := range spec.CapDrop {
+		args = append(args, "--cap-drop", capability)
+	}
+	for _, option := range spec.SecurityOpt {
+		args = append(args, "--security-opt", option)
+	}
+	for _, ulimit := range spec.Ulimits {
+		args = append(args, "--ulimit", fmt.Sprintf("%s=%d:%d", ulimit.Name, ulimit.Soft, ulimit.Hard))
+	}
+	if spec.ShmSize > 0 {
+		args = append(args, "--shm-size", strconv.FormatInt(spec.ShmSize, 10))
+	}
+	for _, key := range sortedKeys(spec.Sysctls) {
+		args = append(args, "--sysctl", key+"="+spec.Sysctls[key])
+	}
+	for _, mountPath := range sortedKeys(spec.Tmpfs) {
+		if options := spec.Tmpfs[mountPath]; options != "" {
+			args = append(args, "--tmpfs", mountPath+":"+options)
+		} else {
+			args = append(args, "--tmpfs", mountPath)
+		}
+	}
+	if spec.ReadonlyRootfs {
+		args = append(args, "--read-only")
+	}
+	if spec.StopSignal != "" {
+		args = append(args, "--stop-signal", spec.StopSignal)
+	}
+	if spec.StopTimeout > 0 {
+		args = append(args, "--stop-timeout", strconv.Itoa(int(spec.StopTimeout)))
+	}
+	if len(spec.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", spec.Entrypoint[0])
+	}
+
+	args = append(args, spec.Image)
+	if len(spec.Entrypoint) > 1 {
+		args = append(args, spec.Entrypoint[1:]...)
+	}
+	return append(args, spec.Cmd...)
+}
+
+// DockerRunCommand renders the spec as a docker run command line quoted for a POSIX shell
+func (spec *NewContainerSpec) DockerRunCommand() string {
+	args := spec.DockerRunArgs()
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func formatPortMapping(binding PortBindings) string {
+	mapping := strconv.Itoa(int(binding.Container))
+	if binding.Host != 0 || binding.HostIP != "" {
+		host := ""
+		if binding.Host != 0 {
+			host = strconv.Itoa(int(binding.Host))
+		}
+		mapping = host + ":" + mapping
+		if binding.HostIP != "" {
+			hostIP := binding.HostIP
+			if strings.Contains(hostIP, ":") {
+				hostIP = "[" + hostIP + "]"
+			}
+			mapping = hostIP + ":" + mapping
+		}
+	}
+	if protocol := strings.ToLower(binding.Protocol); protocol != "" && protocol != "tcp" {
+		mapping += "/" + protocol
+	}
+	return mapping
+}
+
+func formatVolumeMapping(volume Volumes) string {
+	mapping := volume.Destination
+	switch volume.Type {
+	case "host", "bind":
+		mapping = volume.Source + ":" + mapping
+	case "volume":
+		mapping = volume.Name + ":" + mapping
+	}
+	if volume.Permission == "ro" {
+		mapping += ":ro"
+	}
+	return mapping
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// shellQuote quotes s for a POSIX shell when it contains anything but safe characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=,@%+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
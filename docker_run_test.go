@@ -0,0 +1,244 @@
+package qnap
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// defaultRunSpec returns the spec ParseDockerRun starts from, with image and cmd set
+func defaultRunSpec(image string, cmd ...string) NewContainerSpec {
+	return NewContainerSpec{
+		Type:        "docker",
+		Network:     "bridge",
+		NetworkType: "default",
+		Image:       image,
+		Cmd:         cmd,
+	}
+}
+
+func TestParseDockerRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want func() NewContainerSpec
+	}{
+		{
+			name: "grouped short flags",
+			args: "docker run -itd --rm alpine sh",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("alpine", "sh")
+				spec.Tty = true
+				spec.OpenStdin = true
+				spec.AutoRemove = true
+				return spec
+			},
+		},
+		{
+			name: "attached short flag values",
+			args: "-p80:80 -eTZ=UTC -m512m -v/share/web:/usr/share/nginx/html:ro nginx",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("nginx")
+				spec.PortBindings = []PortBindings{{Host: 80, Container: 80, Protocol: "tcp"}}
+				spec.Env = map[string]string{"TZ": "UTC"}
+				spec.MemLimit = 512
+				spec.Volumes = []Volumes{{Type: "host", Source: "/share/web", Destination: "/usr/share/nginx/html", Permission: "ro"}}
+				return spec
+			},
+		},
+		{
+			name: "grouped flags ending with a value flag",
+			args: "-itp 8080:80 nginx",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("nginx")
+				spec.Tty = true
+				spec.OpenStdin = true
+				spec.PortBindings = []PortBindings{{Host: 8080, Container: 80, Protocol: "tcp"}}
+				return spec
+			},
+		},
+		{
+			name: "port forms",
+			args: "-p [::1]:80:80 -p 127.0.0.1::443 -p 53:53/udp -p 9000 -p 0.0.0.0:7000-7002:8000-8002/tcp nginx",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("nginx")
+				spec.PortBindings = []PortBindings{
+					{Host: 80, Container: 80, Protocol: "tcp", HostIP: "::1"},
+					{Container: 443, Protocol: "tcp", HostIP: "127.0.0.1"},
+					{Host: 53, Container: 53, Protocol: "udp"},
+					{Container: 9000, Protocol: "tcp"},
+					{Host: 7000, Container: 8000, Protocol: "tcp", HostIP: "0.0.0.0"},
+					{Host: 7001, Container: 8001, Protocol: "tcp", HostIP: "0.0.0.0"},
+					{Host: 7002, Container: 8002, Protocol: "tcp", HostIP: "0.0.0.0"},
+				}
+				return spec
+			},
+		},
+		{
+			name: "volume forms",
+			args: "-v data:/var/lib/data -v /cache -v /tmp/x:ro --volume=/share/conf:/etc/app:ro,z app",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("app")
+				spec.Volumes = []Volumes{
+					{Type: "volume", Name: "data", Destination: "/var/lib/data", Permission: "rw"},
+					{Type: "new", Destination: "/cache", Permission: "rw"},
+					{Type: "new", Destination: "/tmp/x", Permission: "ro"},
+					{Type: "host", Source: "/share/conf", Destination: "/etc/app", Permission: "ro"},
+				}
+				return spec
+			},
+		},
+		{
+			name: "long flags with equals and resources",
+			args: "docker container run --name=web --restart on-failure:5 --network=qnet-static --ip 192.168.1.20 " +
+				"--cpus 1.5 --memory-reservation 256m --cpuset-cpus 0-1 --shm-size 64m --privileged=false " +
+				"--device /dev/dri:/dev/dri:rw --pull always --label app=web -l tier=front nginx:1.27",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("nginx:1.27")
+				spec.Name = "web"
+				spec.RestartPolicy = RestartPolicy{Name: "on-failure", MaximumRetryCount: 5}
+				spec.Network = "qnet-static"
+				spec.IPAddress = "192.168.1.20"
+				spec.CPULimit = 150
+				spec.MemReservation = 256
+				spec.Cpupin.CPUIDs = "0-1"
+				spec.ShmSize = 64 << 20
+				spec.Devices = []Devices{{Name: "/dev/dri", Permission: "rw"}}
+				spec.Pull = true
+				spec.Labels = map[string]string{"app": "web", "tier": "front"}
+				return spec
+			},
+		},
+		{
+			name: "security flags",
+			args: "--cap-add NET_ADMIN --cap-drop ALL --security-opt no-new-privileges --ulimit nofile=1024:2048 " +
+				"--ulimit nproc=512 -u 1000:1000 -w /srv --sysctl net.core.somaxconn=1024 --tmpfs /run:size=64m " +
+				"--tmpfs /tmp --read-only --stop-signal SIGQUIT --stop-timeout 20 app",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("app")
+				spec.CapAdd = []string{"NET_ADMIN"}
+				spec.CapDrop = []string{"ALL"}
+				spec.SecurityOpt = []string{"no-new-privileges"}
+				spec.Ulimits = []Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}, {Name: "nproc", Soft: 512, Hard: 512}}
+				spec.User = "1000:1000"
+				spec.WorkingDir = "/srv"
+				spec.Sysctls = map[string]string{"net.core.somaxconn": "1024"}
+				spec.Tmpfs = map[string]string{"/run": "size=64m", "/tmp": ""}
+				spec.ReadonlyRootfs = true
+				spec.StopSignal = "SIGQUIT"
+				spec.StopTimeout = 20
+				return spec
+			},
+		},
+		{
+			name: "command after the image and after --",
+			args: "--entrypoint /bin/sh -- alpine -c --not-a-flag",
+			want: func() NewContainerSpec {
+				spec := defaultRunSpec("alpine", "-c", "--not-a-flag")
+				spec.Entrypoint = []string{"/bin/sh"}
+				return spec
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := ParseDockerRun(strings.Fields(test.args))
+			if err != nil {
+				t.Fatalf("ParseDockerRun: %v", err)
+			}
+			if want := test.want(); !reflect.DeepEqual(spec, want) {
+				t.Errorf("spec mismatch\ngot:  %+v\nwant: %+v", spec, want)
+			}
+
+			// parse -> render -> parse keeps the spec
+			args := spec.DockerRunArgs()
+			again, err := ParseDockerRun(args)
+			if err != nil {
+				t.Fatalf("ParseDockerRun(%q): %v", args, err)
+			}
+			if !reflect.DeepEqual(again, spec) {
+				t.Errorf("round trip through %q changed the spec\ngot:  %+v\nwant: %+v", args, again, spec)
+			}
+		})
+	}
+}
+
+func TestParseDockerRunEnvFile(t *testing.T) {
+	t.Setenv("QNAP_TEST_PASSTHROUGH", "from-environment")
+
+	envFile := filepath.Join(t.TempDir(), "app.env")
+	content := "# database\nDB_HOST=db\n\nDB_PASS=s3cret #1\nQNAP_TEST_PASSTHROUGH\nQNAP_TEST_UNSET\nEMPTY=\n"
+	if err := os.WriteFile(envFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := ParseDockerRun([]string{"--env-file", envFile, "-e", "DB_HOST=override", "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"DB_HOST":               "override",
+		"DB_PASS":               "s3cret #1",
+		"QNAP_TEST_PASSTHROUGH": "from-environment",
+		"EMPTY":                 "",
+	}
+	if !reflect.DeepEqual(spec.Env, want) {
+		t.Errorf("env %v, want %v", spec.Env, want)
+	}
+
+	if _, err := ParseDockerRun([]string{"--env-file", filepath.Join(t.TempDir(), "missing.env"), "app"}); err == nil {
+		t.Error("missing env file accepted")
+	}
+}
+
+func TestParseDockerRunErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		problem string
+	}{
+		{name: "no image", args: "-d --name web", problem: "image is missing"},
+		{name: "unknown long flag", args: "--gpus all nginx", problem: "flag --gpus is not supported"},
+		{name: "unknown short flag in a group", args: "-itx nginx", problem: "flag -x is not supported"},
+		{name: "missing value", args: "nginx --name", problem: ""},
+		{name: "flag needs a value", args: "--name", problem: "flag --name needs a value"},
+		{name: "range sizes differ", args: "-p 8000-8001:80 nginx", problem: "port ranges differ in size"},
+		{name: "descending range", args: "-p 81-80 nginx", problem: "port range is descending"},
+		{name: "bad IPv6 address", args: "-p [::1:80 nginx", problem: "invalid IPv6 host address"},
+		{name: "bad volume option", args: "-v data:/data:rx nginx", problem: "volume option rx is not supported"},
+		{name: "bad memory", args: "-m lots nginx", problem: "-m lots"},
+		{name: "bad pull policy", args: "--pull sometimes nginx", problem: "pull policy"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := ParseDockerRun(strings.Fields(test.args))
+			if test.problem == "" {
+				// Everything after the image is its command
+				if err != nil || spec.Image != "nginx" || len(spec.Cmd) != 1 {
+					t.Errorf("ParseDockerRun = %+v, %v", spec, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("error %v, want it to contain %q", err, test.problem)
+			}
+		})
+	}
+}
+
+func TestDockerRunCommand(t *testing.T) {
+	spec := defaultRunSpec("alpine", "sh", "-c", "echo 'hi' && sleep 1")
+	spec.Name = "greeter"
+	spec.Env = map[string]string{"B": "2", "A": "x y"}
+	spec.PortBindings = []PortBindings{{Host: 80, Container: 80, Protocol: "tcp", HostIP: "::1"}}
+
+	want := `docker run -d --name greeter -p '[::1]:80:80' -e 'A=x y' -e B=2 alpine sh -c 'echo '\''hi'\'' && sleep 1'`
+	if got := spec.DockerRunCommand(); got != want {
+		t.Errorf("DockerRunCommand\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,246 @@
+package qnap
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EventType is the kind of a container or application event
+type EventType string
+
+const (
+	EventCreate       EventType = "create"        // A container appeared
+	EventStart        EventType = "start"         // A container started running
+	EventStop         EventType = "stop"          // A container stopped with exit code 0
+	EventDie          EventType = "die"           // A container stopped with a non-zero exit code
+	EventDestroy      EventType = "destroy"       // A container was removed
+	EventHealthStatus EventType = "health_status" // The healthcheck status of a running container changed
+	EventAppStatus    EventType = "app_status"    // The status of an application changed, it appeared or it was removed
+	EventError        EventType = "error"         // Polling kept failing, the last event before the channel is closed
+)
+
+// Event represents a change observed in Container Station
+type Event struct {
+	Type           EventType
+	Time           time.Time
+	ContainerID    string // Empty for application events
+	ContainerName  string // Empty for application events
+	ContainerType  string // Empty for application events
+	App            string // Only set for application events
+	Status         string // The new container or application status, empty once removed
+	PreviousStatus string // The status before the change, empty for new containers and applications
+	Health         string // The new health status, only set for health_status events
+	ExitCode       int32  // The exit code, only set for stop and die events
+	Err            error  // Why polling stopped, only set for error events
+}
+
+// EventFilter selects the events returned by Events, empty fields match every event
+type EventFilter struct {
+	Types      []EventType   // Event types to report
+	Containers []string      // Container IDs or names to report container events for
+	Apps       []string      // Application names to report app_status events for
+	Interval   time.Duration // Polling interval, two seconds when zero
+}
+
+// eventSnapshotContainer is the state of a container in one overview snapshot
+type eventSnapshotContainer struct {
+	Name   string
+	Type   string
+	Status string
+	Health string
+}
+
+// Events reports container and application changes until ctx is cancelled.
+// Container Station has no event endpoint, so events are derived by diffing consecutive overview snapshots.
+// Changes between two polls are merged, for example a quick restart is not reported.
+// A failed poll is retried on the next tick, after maxPollFailures failures in a row an error event
+// is sent whatever the filter and the channel is closed
+func (c *Client) Events(ctx context.Context, filter EventFilter) (<-chan Event, error) {
+	interval := filter.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	previousContainers, previousApps, err := c.eventSnapshot(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		failures := 0
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			containers, apps, err := c.eventSnapshot(filter)
+			if err != nil {
+				failures++
+				if failures < maxPollFailures {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+				case events <- Event{Type: EventError, Time: time.Now(), Err: fmt.Errorf("events polling failed %d times: %w", failures, err)}:
+				}
+				return
+			}
+			failures = 0
+
+			for _, event := range c.diffEventSnapshots(filter, previousContainers, containers, previousApps, apps) {
+				select {
+				case <-ctx.Done():
+					return
+				case events <- event:
+				}
+			}
+			previousContainers, previousApps = containers, apps
+		}
+	}()
+
+	return events, nil
+}
+
+// eventSnapshot reads the overview, and the health of running containers when health events are wanted
+func (c *Client) eventSnapshot(filter EventFilter) (map[string]eventSnapshotContainer, map[string]string, error) {
+	overview, err := c.GetContainerStationOverview()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	containers := make(map[string]eventSnapshotContainer, len(overview.Data.Container))
+	for _, container := range overview.Data.Container {
+		snapshot := eventSnapshotContainer{
+			Name:   container.Name,
+			Type:   container.Type,
+			Status: container.Status,
+		}
+		if filter.wants(EventHealthStatus) && filter.wantsContainer(container.ID, container.Name) && container.Status == ContainerStatusRunning {
+			containerInfo, err := c.InspectContainer(container.ID, container.Type, &c.Token)
+			if err != nil {
+				return nil, nil, err
+			}
+			snapshot.Health = containerInfo.Data.DockerStatus.Health
+		}
+		containers[container.ID] = snapshot
+	}
+
+	apps := make(map[string]string, len(overview.Data.App))
+	for _, app := range overview.Data.App {
+		apps[app.Name] = app.Status
+	}
+	return containers, apps, nil
+}
+
+// diffEventSnapshots returns the events between two snapshots that pass the filter
+func (c *Client) diffEventSnapshots(filter EventFilter, previousContainers, containers map[string]eventSnapshotContainer, previousApps, apps map[string]string) []Event {
+	now := time.Now()
+	var events []Event
+
+	addContainerEvent := func(eventType EventType, id string, container eventSnapshotContainer, previousStatus string) *Event {
+		if !filter.wants(eventType) || !filter.wantsContainer(id, container.Name) {
+			return nil
+		}
+		events = append(events, Event{
+			Type:           eventType,
+			Time:           now,
+			ContainerID:    id,
+			ContainerName:  container.Name,
+			ContainerType:  container.Type,
+			Status:         container.Status,
+			PreviousStatus: previousStatus,
+		})
+		return &events[len(events)-1]
+	}
+
+	for id, container := range containers {
+		previous, existed := previousContainers[id]
+		if !existed {
+			addContainerEvent(EventCreate, id, container, "")
+			if container.Status == ContainerStatusRunning {
+				addContainerEvent(EventStart, id, container, "")
+			}
+			continue
+		}
+
+		if container.Status != previous.Status {
+			switch {
+			case container.Status == ContainerStatusRunning && previous.Status != "paused":
+				addContainerEvent(EventStart, id, container, previous.Status)
+			case isActiveStatus(previous.Status) && !isActiveStatus(container.Status):
+				if !filter.wants(EventStop) && !filter.wants(EventDie) {
+					break
+				}
+				// Tell a clean stop from a crash by the exit code
+				var exitCode int32
+				containerInfo, err := c.InspectContainer(id, container.Type, &c.Token)
+				if err == nil {
+					exitCode = containerInfo.Data.DockerStatus.ExitCode
+				}
+				eventType := EventStop
+				if exitCode != 0 {
+					eventType = EventDie
+				}
+				if event := addContainerEvent(eventType, id, container, previous.Status); event != nil {
+					event.ExitCode = exitCode
+				}
+			}
+		}
+
+		if container.Health != previous.Health && container.Health != "" {
+			if event := addContainerEvent(EventHealthStatus, id, container, previous.Status); event != nil {
+				event.Health = container.Health
+			}
+		}
+	}
+
+	for id, previous := range previousContainers {
+		if _, exists := containers[id]; !exists {
+			removed := previous
+			removed.Status = ""
+			addContainerEvent(EventDestroy, id, removed, previous.Status)
+		}
+	}
+
+	if filter.wants(EventAppStatus) {
+		for name, status := range apps {
+			if previousStatus, existed := previousApps[name]; (!existed || previousStatus != status) && filter.wantsApp(name) {
+				events = append(events, Event{Type: EventAppStatus, Time: now, App: name, Status: status, PreviousStatus: previousStatus})
+			}
+		}
+		for name, previousStatus := range previousApps {
+			if _, exists := apps[name]; !exists && filter.wantsApp(name) {
+				events = append(events, Event{Type: EventAppStatus, Time: now, App: name, PreviousStatus: previousStatus})
+			}
+		}
+	}
+
+	return events
+}
+
+// isActiveStatus reports whether a container has a process, which is the case while it runs or is paused
+func isActiveStatus(status string) bool {
+	return status == ContainerStatusRunning || status == "paused"
+}
+
+func (filter EventFilter) wants(eventType EventType) bool {
+	return len(filter.Types) == 0 || slices.Contains(filter.Types, eventType)
+}
+
+func (filter EventFilter) wantsContainer(id string, name string) bool {
+	return len(filter.Containers) == 0 || slices.Contains(filter.Containers, id) || slices.Contains(filter.Containers, name)
+}
+
+func (filter EventFilter) wantsApp(name string) bool {
+	return len(filter.Apps) == 0 || slices.Contains(filter.Apps, name)
+}
@@ -0,0 +1,181 @@
+package qnap
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffEventSnapshots(t *testing.T) {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "clean", Name: "clean", Type: "docker", Status: "stopped",
+		Spec: map[string]any{"dockerStatus": map[string]any{"exitCode": 0}}})
+	station.add(&fakeContainer{ID: "crashed", Name: "crashed", Type: "docker", Status: "stopped",
+		Spec: map[string]any{"dockerStatus": map[string]any{"exitCode": 137}}})
+	client := station.client()
+
+	running := eventSnapshotContainer{Type: "docker", Status: "running"}
+	paused := eventSnapshotContainer{Type: "docker", Status: "paused"}
+	stopped := eventSnapshotContainer{Type: "docker", Status: "stopped"}
+	named := func(container eventSnapshotContainer, name string) eventSnapshotContainer {
+		container.Name = name
+		return container
+	}
+	healthy := named(running, "web")
+	healthy.Health = "healthy"
+
+	tests := []struct {
+		name              string
+		filter            EventFilter
+		previous, current map[string]eventSnapshotContainer
+		previousApps      map[string]string
+		apps              map[string]string
+		want              []Event
+	}{
+		{
+			name:    "created running and stopped",
+			current: map[string]eventSnapshotContainer{"a": named(running, "a"), "b": named(stopped, "b")},
+			want: []Event{
+				{Type: EventCreate, ContainerID: "a", ContainerName: "a", ContainerType: "docker", Status: "running"},
+				{Type: EventCreate, ContainerID: "b", ContainerName: "b", ContainerType: "docker", Status: "stopped"},
+				{Type: EventStart, ContainerID: "a", ContainerName: "a", ContainerType: "docker", Status: "running"},
+			},
+		},
+		{
+			name:     "stopped cleanly and crashed",
+			previous: map[string]eventSnapshotContainer{"clean": named(running, "clean"), "crashed": named(running, "crashed")},
+			current:  map[string]eventSnapshotContainer{"clean": named(stopped, "clean"), "crashed": named(stopped, "crashed")},
+			want: []Event{
+				{Type: EventDie, ContainerID: "crashed", ContainerName: "crashed", ContainerType: "docker", Status: "stopped", PreviousStatus: "running", ExitCode: 137},
+				{Type: EventStop, ContainerID: "clean", ContainerName: "clean", ContainerType: "docker", Status: "stopped", PreviousStatus: "running"},
+			},
+		},
+		{
+			name:     "paused container stopped or killed",
+			previous: map[string]eventSnapshotContainer{"clean": named(paused, "clean"), "crashed": named(paused, "crashed")},
+			current:  map[string]eventSnapshotContainer{"clean": named(stopped, "clean"), "crashed": named(stopped, "crashed")},
+			want: []Event{
+				{Type: EventDie, ContainerID: "crashed", ContainerName: "crashed", ContainerType: "docker", Status: "stopped", PreviousStatus: "paused", ExitCode: 137},
+				{Type: EventStop, ContainerID: "clean", ContainerName: "clean", ContainerType: "docker", Status: "stopped", PreviousStatus: "paused"},
+			},
+		},
+		{
+			name:     "pause and resume are not start or stop",
+			previous: map[string]eventSnapshotContainer{"a": named(running, "a"), "b": named(paused, "b")},
+			current:  map[string]eventSnapshotContainer{"a": named(paused, "a"), "b": named(running, "b")},
+		},
+		{
+			name:     "started",
+			previous: map[string]eventSnapshotContainer{"a": named(stopped, "a")},
+			current:  map[string]eventSnapshotContainer{"a": named(running, "a")},
+			want: []Event{
+				{Type: EventStart, ContainerID: "a", ContainerName: "a", ContainerType: "docker", Status: "running", PreviousStatus: "stopped"},
+			},
+		},
+		{
+			name:     "destroyed",
+			previous: map[string]eventSnapshotContainer{"a": named(paused, "a")},
+			want: []Event{
+				{Type: EventDestroy, ContainerID: "a", ContainerName: "a", ContainerType: "docker", PreviousStatus: "paused"},
+			},
+		},
+		{
+			name:     "health changed",
+			previous: map[string]eventSnapshotContainer{"w": named(running, "web")},
+			current:  map[string]eventSnapshotContainer{"w": healthy},
+			want: []Event{
+				{Type: EventHealthStatus, ContainerID: "w", ContainerName: "web", ContainerType: "docker", Status: "running", PreviousStatus: "running", Health: "healthy"},
+			},
+		},
+		{
+			name:         "application status",
+			previousApps: map[string]string{"shop": "running", "old": "running", "same": "running"},
+			apps:         map[string]string{"shop": "stopped", "new": "running", "same": "running"},
+			want: []Event{
+				{Type: EventAppStatus, App: "new", Status: "running"},
+				{Type: EventAppStatus, App: "old", PreviousStatus: "running"},
+				{Type: EventAppStatus, App: "shop", Status: "stopped", PreviousStatus: "running"},
+			},
+		},
+		{
+			name:         "filtered",
+			filter:       EventFilter{Types: []EventType{EventStop, EventAppStatus}, Containers: []string{"clean"}, Apps: []string{"shop"}},
+			previous:     map[string]eventSnapshotContainer{"clean": named(running, "clean"), "crashed": named(running, "crashed"), "a": named(stopped, "a")},
+			current:      map[string]eventSnapshotContainer{"clean": named(stopped, "clean"), "crashed": named(stopped, "crashed"), "a": named(running, "a")},
+			previousApps: map[string]string{"shop": "running", "other": "running"},
+			apps:         map[string]string{"shop": "stopped"},
+			want: []Event{
+				{Type: EventAppStatus, App: "shop", Status: "stopped", PreviousStatus: "running"},
+				{Type: EventStop, ContainerID: "clean", ContainerName: "clean", ContainerType: "docker", Status: "stopped", PreviousStatus: "running"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events := client.diffEventSnapshots(test.filter, test.previous, test.current, test.previousApps, test.apps)
+			for i := range events {
+				if events[i].Time.IsZero() {
+					t.Errorf("event %+v has no time", events[i])
+				}
+				events[i].Time = time.Time{}
+			}
+			sort.Slice(events, func(i, j int) bool {
+				if events[i].Type != events[j].Type {
+					return events[i].Type < events[j].Type
+				}
+				return events[i].ContainerID+events[i].App < events[j].ContainerID+events[j].App
+			})
+			if len(events) == 0 && len(test.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(events, test.want) {
+				t.Errorf("events:\n%+v\nwant:\n%+v", events, test.want)
+			}
+		})
+	}
+}
+
+func TestEventsPollingFails(t *testing.T) {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "c1", Name: "web", Type: "docker", Status: "stopped"})
+	client := station.client()
+
+	events, err := client.Events(context.Background(), EventFilter{Types: []EventType{EventStart}, Interval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+
+	next := func() (Event, bool) {
+		t.Helper()
+		select {
+		case event, ok := <-events:
+			return event, ok
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event")
+			return Event{}, false
+		}
+	}
+
+	// A short outage is skipped and events keep coming
+	station.fail(maxPollFailures - 1)
+	station.mu.Lock()
+	station.find("c1").Status = "running"
+	station.mu.Unlock()
+	if event, ok := next(); !ok || event.Type != EventStart || event.ContainerID != "c1" {
+		t.Fatalf("event %+v, %v, want start of c1", event, ok)
+	}
+
+	// Polling that keeps failing ends the stream with an error event, although the filter only wants start
+	station.fail(maxPollFailures)
+	event, ok := next()
+	if !ok || event.Type != EventError || event.Err == nil || !strings.Contains(event.Err.Error(), "events polling failed 5 times: status: 500") {
+		t.Fatalf("event %+v, %v, want the polling error", event, ok)
+	}
+	if event, ok := next(); ok {
+		t.Errorf("event %+v after the error", event)
+	}
+}
@@ -0,0 +1,431 @@
+package qnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeContainer is a container known to fakeStation
+type fakeContainer struct {
+	ID       string
+	Name     string
+	Type     string
+	Status   string
+	Spec     map[string]any   // The create payload the container was created from
+	Networks []map[string]any // The attached networks as inspect returns them
+	Logs     string           // The log output, followed logs stay open after it
+	Broken   bool             // The log stream is aborted after Logs
+}
+
+// fakeStation is an in-memory Container Station serving the overview, task, container create,
+// inspect, state change, resource, network and log endpoints, and application create and inspect. Tasks complete immediately
+type fakeStation struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	containers []*fakeContainer
+	apps       map[string][]string // Container IDs of each application
+	appYml     map[string]string   // Compose YAML of each application
+	tasks      []string
+	created    int               // Number of containers created, numbers their IDs
+	addresses  int               // Number of network addresses assigned
+	requests   []string          // "METHOD path?query" of every request
+	inspectKey map[string]string // Renames create payload keys in inspect responses
+	failing    int               // Number of next requests failing with 500
+	imageState map[string]string // Status of containers created from an image, running otherwise
+}
+
+func newFakeStation(t *testing.T) *fakeStation {
+	station := &fakeStation{t: t, apps: map[string][]string{}, appYml: map[string]string{}, inspectKey: map[string]string{}, imageState: map[string]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /container-station/api/v3/overview", station.overview)
+	mux.HandleFunc("GET /container-station/api/v3/tasks", station.taskList)
+	mux.HandleFunc("GET /container-station/api/v3/containers", station.list)
+	mux.HandleFunc("POST /container-station/api/v3/containers", station.create)
+	mux.HandleFunc("DELETE /container-station/api/v3/containers", station.remove)
+	mux.HandleFunc("GET /container-station/api/v3/containers/{type}", station.inspect)
+	mux.HandleFunc("PUT /container-station/api/v3/containers/{operation}", station.changeState)
+	mux.HandleFunc("GET /container-station/api/v3/containers/{type}/logs", station.logs)
+	mux.HandleFunc("PUT /container-station/api/v3/containers/{type}/resource", station.updateResources)
+	mux.HandleFunc("PUT /container-station/api/v3/containers/{type}/networks/{operation}", station.changeNetwork)
+	mux.HandleFunc("GET /container-station/api/v3/apps/{name}/inspect", station.inspectApp)
+	mux.HandleFunc("POST /container-station/api/v3/apps/compose", station.createApp)
+
+	station.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		station.mu.Lock()
+		station.requests = append(station.requests, r.Method+" "+r.URL.RequestURI())
+		failing := station.failing > 0
+		if failing {
+			station.failing--
+		}
+		station.mu.Unlock()
+
+		if failing {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			http.Error(w, "missing token", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(station.server.Close)
+	return station
+}
+
+// client returns a client signed in to the fake station
+func (station *fakeStation) client() *Client {
+	return &Client{
+		HostURL:    station.server.URL,
+		HTTPClient: station.server.Client(),
+		Token:      "NAS_SID=fake-session",
+	}
+}
+
+// add registers an existing container
+func (station *fakeStation) add(container *fakeContainer) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+	station.containers = append(station.containers, container)
+}
+
+// addApp registers an application of existing containers
+func (station *fakeStation) addApp(name string, containerIDs ...string) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+	station.apps[name] = containerIDs
+}
+
+// fail makes the next requests fail
+func (station *fakeStation) fail(requests int) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+	station.failing = requests
+}
+
+func (station *fakeStation) find(id string) *fakeContainer {
+	for _, container := range station.containers {
+		if container.ID == id {
+			return container
+		}
+	}
+	return nil
+}
+
+func (station *fakeStation) newTask() string {
+	taskID := fmt.Sprintf("task-%d", len(station.tasks)+1)
+	station.tasks = append(station.tasks, taskID)
+	return taskID
+}
+
+func (station *fakeStation) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		station.t.Errorf("encode response: %v", err)
+	}
+}
+
+func (station *fakeStation) overview(w http.ResponseWriter, r *http.Request) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	containers := []map[string]any{}
+	for _, container := range station.containers {
+		containers = append(containers, map[string]any{
+			"id": container.ID, "name": container.Name, "type": container.Type, "status": container.Status,
+		})
+	}
+	apps := []map[string]any{}
+	for name := range station.apps {
+		apps = append(apps, map[string]any{"name": name, "status": "running"})
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"app": apps, "container": containers}})
+}
+
+func (station *fakeStation) list(w http.ResponseWriter, r *http.Request) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	items := []map[string]any{}
+	for _, container := range station.containers {
+		items = append(items, map[string]any{
+			"id": container.ID, "name": container.Name, "type": container.Type, "status": container.Status,
+		})
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"items": items}})
+}
+
+// setSpec changes a create payload value of a container, which inspect responses return
+func (station *fakeStation) setSpec(id string, key string, value any) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	container := station.find(id)
+	if container.Spec == nil {
+		container.Spec = map[string]any{}
+	}
+	container.Spec[key] = value
+}
+
+func (station *fakeStation) taskList(w http.ResponseWriter, r *http.Request) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	items := []map[string]any{}
+	for _, taskID := range station.tasks {
+		items = append(items, map[string]any{"id": taskID, "state": TaskStatusCompleted})
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"items": items}})
+}
+
+func (station *fakeStation) create(w http.ResponseWriter, r *http.Request) {
+	var spec map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	name, _ := spec["name"].(string)
+	containerType, _ := spec["type"].(string)
+	if spec["operation"] == "recreate" {
+		for i, existing := range station.containers {
+			if existing.Name == name {
+				station.containers = append(station.containers[:i], station.containers[i+1:]...)
+				break
+			}
+		}
+	}
+	station.created++
+	container := &fakeContainer{
+		ID:     fmt.Sprintf("%s-%d", containerType, station.created),
+		Name:   name,
+		Type:   containerType,
+		Status: "running",
+		Spec:   spec,
+	}
+	if image, _ := spec["image"].(string); station.imageState[image] != "" {
+		container.Status = station.imageState[image]
+	}
+	// The spec network becomes the first network, the fake assigns an address unless it is static
+	if network, _ := spec["network"].(string); network != "" {
+		address, _ := spec["ipAddress"].(string)
+		container.Networks = []map[string]any{station.network(network, spec["networktype"], address)}
+	}
+	for _, key := range []string{"network", "networktype", "ipAddress"} {
+		delete(spec, key)
+	}
+	station.containers = append(station.containers, container)
+	station.writeJSON(w, map[string]any{"data": map[string]any{"taskID": station.newTask()}})
+}
+
+func (station *fakeStation) inspect(w http.ResponseWriter, r *http.Request) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	container := station.find(r.URL.Query().Get("id"))
+	if container == nil || container.Type != r.PathValue("type") {
+		http.Error(w, "container not found", http.StatusNotFound)
+		return
+	}
+
+	data := map[string]any{}
+	for key, value := range container.Spec {
+		if renamed, ok := station.inspectKey[key]; ok {
+			key = renamed
+		}
+		data[key] = value
+	}
+	data["networks"] = container.Networks
+	data["id"] = container.ID
+	data["name"] = container.Name
+	data["type"] = container.Type
+	data["status"] = container.Status
+	station.writeJSON(w, map[string]any{"data": data})
+}
+
+func (station *fakeStation) changeState(w http.ResponseWriter, r *http.Request) {
+	var payload ChangeContainer
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	operation := r.PathValue("operation")
+	if _, ok := containerOperationStatus[operation]; !ok {
+		http.Error(w, "unknown operation", http.StatusNotFound)
+		return
+	}
+	status := expectedContainerStatus(operation, payload.Data.Signal)
+	for _, item := range payload.Data.Items {
+		container := station.find(item.CID)
+		if container == nil || container.Type != item.CType {
+			http.Error(w, "container not found", http.StatusNotFound)
+			return
+		}
+		if status != "" {
+			container.Status = status
+		}
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"taskID": station.newTask()}})
+}
+
+func (station *fakeStation) remove(w http.ResponseWriter, r *http.Request) {
+	var payload RemoveContainer
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	for _, item := range payload.Data.Items {
+		for i, container := range station.containers {
+			if container.ID == item.CID && container.Type == item.CType {
+				station.containers = append(station.containers[:i], station.containers[i+1:]...)
+				break
+			}
+		}
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"taskID": station.newTask()}})
+}
+
+func (station *fakeStation) logs(w http.ResponseWriter, r *http.Request) {
+	station.mu.Lock()
+	container := station.find(r.URL.Query().Get("id"))
+	station.mu.Unlock()
+	if container == nil || container.Type != r.PathValue("type") {
+		http.Error(w, "container not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprint(w, container.Logs)
+	w.(http.Flusher).Flush()
+	if container.Broken {
+		panic(http.ErrAbortHandler)
+	}
+	if r.URL.Query().Get("follow") == "true" {
+		<-r.Context().Done()
+	}
+}
+
+func (station *fakeStation) inspectApp(w http.ResponseWriter, r *http.Request) {
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	containerIDs, ok := station.apps[r.PathValue("name")]
+	if !ok {
+		http.Error(w, "application not found", http.StatusNotFound)
+		return
+	}
+	containers := []map[string]any{}
+	for _, id := range containerIDs {
+		containers = append(containers, map[string]any{"id": id, "name": station.find(id).Name})
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"yml": station.appYml[r.PathValue("name")], "containers": containers}})
+}
+
+// updateResources sets the limits of a container, a limit missing from the request is removed
+func (station *fakeStation) updateResources(w http.ResponseWriter, r *http.Request) {
+	var update map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	container := station.find(r.URL.Query().Get("id"))
+	if container == nil || container.Type != r.PathValue("type") {
+		http.Error(w, "container not found", http.StatusNotFound)
+		return
+	}
+	if container.Spec == nil {
+		container.Spec = map[string]any{}
+	}
+	for _, key := range []string{"cpuLimit", "memLimit", "memReservation"} {
+		container.Spec[key] = update[key]
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"taskID": station.newTask()}})
+}
+
+// createApp creates or recreates an application, keeping the containers of a recreated one
+func (station *fakeStation) createApp(w http.ResponseWriter, r *http.Request) {
+	var application NewAppReqModel
+	if err := json.NewDecoder(r.Body).Decode(&application); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	if _, exists := station.apps[application.Name]; !exists {
+		station.apps[application.Name] = []string{}
+	}
+	station.appYml[application.Name] = application.Yml
+	station.writeJSON(w, map[string]any{"data": map[string]any{"taskID": station.newTask()}})
+}
+
+// network returns a network as inspect reports it, an empty address is assigned and not static
+func (station *fakeStation) network(name string, networkType any, address string) map[string]any {
+	static := address != ""
+	if !static {
+		station.addresses++
+		address = fmt.Sprintf("172.17.0.%d", station.addresses+1)
+	}
+	if networkType == nil || networkType == "" {
+		networkType = "default"
+	}
+	return map[string]any{"id": "net-" + name, "name": name, "displayName": name, "networkType": networkType,
+		"ipAddress": address, "isStaticIP": static}
+}
+
+func (station *fakeStation) changeNetwork(w http.ResponseWriter, r *http.Request) {
+	var payload ContainerNetworkData
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	station.mu.Lock()
+	defer station.mu.Unlock()
+
+	container := station.find(r.URL.Query().Get("id"))
+	if container == nil || container.Type != r.PathValue("type") {
+		http.Error(w, "container not found", http.StatusNotFound)
+		return
+	}
+	switch r.PathValue("operation") {
+	case "connect":
+		container.Networks = append(container.Networks, station.network(payload.Network, nil, payload.IPAddress))
+	case "disconnect":
+		for i, network := range container.Networks {
+			if network["name"] == payload.Network {
+				container.Networks = append(container.Networks[:i], container.Networks[i+1:]...)
+				break
+			}
+		}
+	default:
+		http.Error(w, "unknown operation", http.StatusNotFound)
+		return
+	}
+	station.writeJSON(w, map[string]any{"data": map[string]any{"taskID": station.newTask()}})
+}
@@ -1,3 +1,8 @@
 module github.com/mohamed-mfarag/qnap-client-lib
 
 go 1.22.5
+
+require (
+	github.com/gorilla/websocket v1.5.3
+	gopkg.in/yaml.v3 v3.0.1
+)
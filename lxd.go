@@ -0,0 +1,71 @@
+package qnap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ContainerTypeLXD is the container type of LXD containers, Docker containers use "docker"
+const ContainerTypeLXD = "lxd"
+
+// CreateLXDContainer creates a new LXD container
+func (c *Client) CreateLXDContainer(container LXDContainerSpec, authToken *string) (*LXDContainerInfo, error) {
+	if container.Type == "" {
+		container.Type = ContainerTypeLXD
+	}
+	if container.Type != ContainerTypeLXD {
+		return nil, errors.New("LXD container spec must have type " + ContainerTypeLXD)
+	}
+
+	rb, err := json.Marshal(container)
+	if err != nil {
+		return nil, err
+	}
+
+	containerID, _, err := c.submitContainer(context.Background(), rb, container.Name, container.Operation)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.InspectLXDContainer(containerID, authToken)
+}
+
+// InspectLXDContainer returns specific LXD container specifications
+func (c *Client) InspectLXDContainer(containerID string, authToken *string) (*LXDContainerInfo, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/container-station/api/v3/containers/%s?id=%s",
+		c.HostURL, ContainerTypeLXD, containerID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, _, err := c.doRequest(req, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var containerData LXDContainerInfo
+	err = json.Unmarshal(body, &containerData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &containerData, nil
+}
+
+// StartLXDContainer starts an LXD container
+func (c *Client) StartLXDContainer(containerID string, authToken *string) (bool, error) {
+	return c.ChangeContainerState(containerID, ContainerTypeLXD, false, "start", authToken)
+}
+
+// StopLXDContainer stops an LXD container
+func (c *Client) StopLXDContainer(containerID string, authToken *string) (bool, error) {
+	return c.ChangeContainerState(containerID, ContainerTypeLXD, false, "stop", authToken)
+}
+
+// DeleteLXDContainer deletes an LXD container
+func (c *Client) DeleteLXDContainer(containerID string, containerVolumeRemove bool, authToken *string) (bool, error) {
+	return c.ChangeContainerState(containerID, ContainerTypeLXD, containerVolumeRemove, "delete", authToken)
+}
@@ -0,0 +1,103 @@
+package qnap
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestLXDContainerLifecycle(t *testing.T) {
+	station := newFakeStation(t)
+	station.inspectKey = map[string]string{"imageserver": "imageServer", "autostart": "autoStart"}
+	client := station.client()
+
+	spec := LXDContainerSpec{
+		Name:        "builder",
+		Image:       "ubuntu/22.04",
+		ImageServer: "https://images.linuxcontainers.org",
+		Profiles:    []string{"default", "docker"},
+		Nesting:     true,
+		Config:      map[string]string{"limits.processes": "500", "security.syscalls.intercept.mknod": "true"},
+		Hostname:    "builder",
+		AutoStart:   true,
+	}
+
+	info, err := client.CreateLXDContainer(spec, &client.Token)
+	if err != nil {
+		t.Fatalf("CreateLXDContainer: %v", err)
+	}
+
+	if len(station.containers) != 1 {
+		t.Fatalf("fake station has %d containers, want 1", len(station.containers))
+	}
+	sent := station.containers[0].Spec
+	wantSent := map[string]any{
+		"type":        ContainerTypeLXD,
+		"imageserver": "https://images.linuxcontainers.org",
+		"profiles":    []any{"default", "docker"},
+		"nesting":     true,
+		"config":      map[string]any{"limits.processes": "500", "security.syscalls.intercept.mknod": "true"},
+	}
+	for key, want := range wantSent {
+		if got, ok := sent[key]; !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("create payload %s = %#v, want %#v", key, got, want)
+		}
+	}
+
+	if info.Data.ID != "lxd-1" || info.Data.Type != ContainerTypeLXD || info.Data.Name != "builder" {
+		t.Errorf("created container %s %s %s", info.Data.ID, info.Data.Type, info.Data.Name)
+	}
+	if info.Data.ImageServer != spec.ImageServer || !info.Data.Nesting || !info.Data.AutoStart ||
+		!slices.Equal(info.Data.Profiles, spec.Profiles) || !reflect.DeepEqual(info.Data.Config, spec.Config) {
+		t.Errorf("inspected container does not match the spec: %+v", info.Data)
+	}
+	if !slices.Contains(station.requests, "GET /container-station/api/v3/containers/lxd?id=lxd-1") {
+		t.Errorf("container was not inspected as lxd, requests: %v", station.requests)
+	}
+
+	stopped, err := client.StopLXDContainer("lxd-1", &client.Token)
+	if err != nil || !stopped {
+		t.Fatalf("StopLXDContainer: %v, %v", stopped, err)
+	}
+	info, err = client.InspectLXDContainer("lxd-1", &client.Token)
+	if err != nil || info.Data.Status != "stopped" {
+		t.Fatalf("status after stop %q, %v", info.Data.Status, err)
+	}
+
+	started, err := client.StartLXDContainer("lxd-1", &client.Token)
+	if err != nil || !started {
+		t.Fatalf("StartLXDContainer: %v, %v", started, err)
+	}
+
+	deleted, err := client.DeleteLXDContainer("lxd-1", false, &client.Token)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteLXDContainer: %v, %v", deleted, err)
+	}
+	if len(station.containers) != 0 {
+		t.Errorf("container still present after delete")
+	}
+}
+
+func TestCreateLXDContainerRejectsDockerType(t *testing.T) {
+	station := newFakeStation(t)
+	client := station.client()
+
+	_, err := client.CreateLXDContainer(LXDContainerSpec{Type: "docker", Name: "wrong", Image: "ubuntu/22.04"}, &client.Token)
+	if err == nil {
+		t.Fatal("CreateLXDContainer accepted a docker spec")
+	}
+	if len(station.requests) != 0 {
+		t.Errorf("requests sent for an invalid spec: %v", station.requests)
+	}
+}
+
+func TestInspectLXDContainerNotFound(t *testing.T) {
+	station := newFakeStation(t)
+	station.add(&fakeContainer{ID: "docker-1", Name: "web", Type: "docker", Status: "running"})
+	client := station.client()
+
+	_, err := client.InspectLXDContainer("docker-1", &client.Token)
+	if err == nil {
+		t.Fatal("InspectLXDContainer returned a docker container")
+	}
+}
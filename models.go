@@ -1,5 +1,7 @@
 package qnap
 
+import "time"
+
 // ContainerStationTaskResponse represents the response structure for Container Station tasks.
 type ContainerStationTaskResponse struct {
 	Data struct {
@@ -102,6 +104,58 @@ type NewContainerSpec struct {
 	PortBindings  []PortBindings    `json:"portbindings"`
 	Cpupin        Cpupin            `json:"cpupin"`
 	RestartPolicy RestartPolicy     `json:"restartpolicy"`
+
+	CPULimit       int32        `json:"cpulimit,omitempty"`
+	MemLimit       int32        `json:"memlimit,omitempty"`
+	MemReservation int32        `json:"memreservation,omitempty"`
+	Healthcheck    *Healthcheck `json:"healthcheck,omitempty"`
+
+	CapAdd         []string          `json:"capadd,omitempty"`
+	CapDrop        []string          `json:"capdrop,omitempty"`
+	SecurityOpt    []string          `json:"securityopt,omitempty"`
+	Ulimits        []Ulimit          `json:"ulimits,omitempty"`
+	ShmSize        int64             `json:"shmsize,omitempty"` // Size of /dev/shm in bytes
+	User           string            `json:"user,omitempty"`
+	WorkingDir     string            `json:"workingdir,omitempty"`
+	Sysctls        map[string]string `json:"sysctls,omitempty"`
+	Tmpfs          map[string]string `json:"tmpfs,omitempty"` // Mount path to tmpfs options, for example "size=64m"
+	ReadonlyRootfs bool              `json:"readonlyrootfs,omitempty"`
+	StopSignal     string            `json:"stopsignal,omitempty"`
+	StopTimeout    int32             `json:"stoptimeout,omitempty"` // Seconds to wait before killing the container, zero uses the default
+
+	// WaitHealthy makes CreateContainer wait up to this long for the healthcheck to report healthy, zero does not wait
+	WaitHealthy time.Duration `json:"-"`
+	// SkipValidation makes CreateContainer submit the spec without running Validate first
+	SkipValidation bool `json:"-"`
+	// SkipPortConflictCheck makes CreateContainer submit the spec without comparing its host ports with existing containers
+	SkipPortConflictCheck bool `json:"-"`
+}
+
+// Ulimit represents the structure for a resource limit of a container.
+type Ulimit struct {
+	Name string `json:"name"`
+	Soft int64  `json:"soft"`
+	Hard int64  `json:"hard"`
+}
+
+// Healthcheck represents the structure for the healthcheck of a container.
+// Test follows the Docker form, for example ["CMD-SHELL", "curl -f http://localhost/ || exit 1"].
+type Healthcheck struct {
+	Test        []string      `json:"test"`
+	Interval    time.Duration `json:"interval"`
+	Timeout     time.Duration `json:"timeout"`
+	Retries     int32         `json:"retries"`
+	StartPeriod time.Duration `json:"startperiod"`
+}
+
+// ResourceUpdate represents the structure for updating the resource limits of an existing container.
+// Fields left nil are unchanged, a limit set to 0 removes it.
+type ResourceUpdate struct {
+	CPULimit       *int32         `json:"cpuLimit,omitempty"`
+	MemLimit       *int32         `json:"memLimit,omitempty"`
+	MemReservation *int32         `json:"memReservation,omitempty"`
+	Cpupin         *Cpupin        `json:"cpupin,omitempty"`
+	RestartPolicy  *RestartPolicy `json:"restartPolicy,omitempty"`
 }
 
 // RestartPolicy represents the structure for the restart policy of a container.
@@ -204,16 +258,17 @@ type ContainerInfo struct {
 			Destination string `json:"destination"`
 			Permission  string `json:"permission"`
 		} `json:"volumes"`
-		AutoRemove   bool    `json:"autoRemove"`
-		Hostname     string  `json:"hostname"`
-		CPU          float32 `json:"cpu"`
-		Memory       float64 `json:"memory"`
-		Tx           float32 `json:"tx"`
-		Rx           float32 `json:"rx"`
-		Read         float32 `json:"read"`
-		Write        float32 `json:"write"`
-		Tty          bool    `json:"tty"`
-		OpenStdin    bool    `json:"openStdin"`
+		AutoRemove   bool         `json:"autoRemove"`
+		Hostname     string       `json:"hostname"`
+		CPU          float32      `json:"cpu"`
+		Memory       float64      `json:"memory"`
+		Tx           float32      `json:"tx"`
+		Rx           float32      `json:"rx"`
+		Read         float32      `json:"read"`
+		Write        float32      `json:"write"`
+		Tty          bool         `json:"tty"`
+		OpenStdin    bool         `json:"openStdin"`
+		Healthcheck  *Healthcheck `json:"healthcheck"`
 		DockerStatus struct {
 			Running    bool   `json:"running"`
 			Paused     bool   `json:"paused"`
@@ -224,6 +279,94 @@ type ContainerInfo struct {
 			FinishedAt string `json:"finishedAt"`
 			Health     string `json:"health"`
 		} `json:"dockerStatus"`
+
+		CapAdd         []string          `json:"capAdd"`
+		CapDrop        []string          `json:"capDrop"`
+		SecurityOpt    []string          `json:"securityOpt"`
+		Ulimits        []Ulimit          `json:"ulimits"`
+		ShmSize        int64             `json:"shmSize"`
+		User           string            `json:"user"`
+		WorkingDir     string            `json:"workingDir"`
+		Sysctls        map[string]string `json:"sysctls"`
+		Tmpfs          map[string]string `json:"tmpfs"`
+		ReadonlyRootfs bool              `json:"readonlyRootfs"`
+		StopSignal     string            `json:"stopSignal"`
+		StopTimeout    int32             `json:"stopTimeout"`
+	} `json:"data"`
+}
+
+// LXDContainerSpec represents the structure for creating a new LXD container.
+type LXDContainerSpec struct {
+	Type           string            `json:"type"`
+	Name           string            `json:"name"`
+	Image          string            `json:"image"`
+	ImageServer    string            `json:"imageserver"`
+	Profiles       []string          `json:"profiles"`
+	Privileged     bool              `json:"privileged"`
+	Nesting        bool              `json:"nesting"`
+	Config         map[string]string `json:"config"`
+	Hostname       string            `json:"hostname"`
+	Network        string            `json:"network"`
+	NetworkType    string            `json:"networktype"`
+	IPAddress      string            `json:"ipAddress"`
+	Devices        []Devices         `json:"devices"`
+	Volumes        []Volumes         `json:"volumes"`
+	Cpupin         Cpupin            `json:"cpupin"`
+	CPULimit       int32             `json:"cpulimit,omitempty"`
+	MemLimit       int32             `json:"memlimit,omitempty"`
+	MemReservation int32             `json:"memreservation,omitempty"`
+	AutoStart      bool              `json:"autostart"`
+	Operation      string            `json:"operation"`
+}
+
+// LXDContainerInfo represents the response structure for LXD container information.
+type LXDContainerInfo struct {
+	Data struct {
+		ID             string            `json:"id"`
+		Name           string            `json:"name"`
+		Type           string            `json:"type"`
+		Image          string            `json:"image"`
+		ImageServer    string            `json:"imageServer"`
+		Status         string            `json:"status"`
+		Architecture   string            `json:"architecture"`
+		Profiles       []string          `json:"profiles"`
+		Privileged     bool              `json:"privileged"`
+		Nesting        bool              `json:"nesting"`
+		Config         map[string]string `json:"config"`
+		Hostname       string            `json:"hostname"`
+		AutoStart      bool              `json:"autoStart"`
+		CPULimit       int32             `json:"cpuLimit"`
+		MemLimit       int32             `json:"memLimit"`
+		MemReservation int32             `json:"memReservation"`
+		Cpupin         struct {
+			CPUIDs string `json:"cpuids"`
+			Type   string `json:"type"`
+		} `json:"cpupin"`
+		Networks []struct {
+			ID          string `json:"id"`
+			Name        string `json:"name"`
+			IPAddress   string `json:"ipAddress"`
+			DisplayName string `json:"displayName"`
+			MacAddress  string `json:"macAddress"`
+			Gateway     string `json:"gateway"`
+			NetworkType string `json:"networkType"`
+			IsStaticIP  bool   `json:"isStaticIP"`
+		} `json:"networks"`
+		Devices []struct {
+			Name       string `json:"name"`
+			Permission string `json:"permission"`
+		} `json:"devices"`
+		Volumes []struct {
+			Type        string `json:"type"`
+			Name        string `json:"name"`
+			Source      string `json:"source"`
+			Destination string `json:"destination"`
+			Permission  string `json:"permission"`
+		} `json:"volumes"`
+		Created   string  `json:"created"`
+		StartedAt string  `json:"startedAt"`
+		CPU       float32 `json:"cpu"`
+		Memory    float64 `json:"memory"`
 	} `json:"data"`
 }
 
@@ -237,6 +380,9 @@ type NewAppReqModel struct {
 	MemLimit       int32                    `json:"mem_limit"`
 	MemReservation int32                    `json:"mem_reservation"`
 	Operation      string                   `json:"operation"`
+
+	// SkipValidation makes CreateApplication submit the YAML without parsing and validating it first
+	SkipValidation bool `json:"-"`
 }
 
 // NewAppReqDefaultURLModel represents the default URL structure for a new application.
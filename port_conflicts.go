@@ -0,0 +1,189 @@
+package qnap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PortConflict represents a host port bound by more than one container
+type PortConflict struct {
+	HostPort   int32                   // The contested host port
+	Protocol   string                  // The protocol of the binding, "tcp" or "udp"
+	Containers []PortConflictContainer // The containers binding the port, on overlapping host IPs
+}
+
+// PortConflictContainer represents a container taking part in a PortConflict
+type PortConflictContainer struct {
+	ID     string
+	Name   string
+	HostIP string
+}
+
+// portBinding is a host side binding of a container, used to compare specs with existing containers
+type portBinding struct {
+	container PortConflictContainer
+	hostPort  int32
+	protocol  string
+}
+
+// FindPortConflicts audits all existing containers and returns the host ports bound more than once
+func (c *Client) FindPortConflicts() ([]PortConflict, error) {
+	containers, err := c.GetContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	var bindings []portBinding
+	for _, container := range containers {
+		for _, binding := range container.PortBindings {
+			bindings = append(bindings, newPortBinding(container.ID, container.Name, binding.HostIP, binding.Host, binding.Protocol))
+		}
+	}
+
+	return groupPortConflicts(bindings, nil), nil
+}
+
+// checkPortConflicts returns an error naming the existing containers whose host ports collide with the spec
+func (c *Client) checkPortConflicts(container NewContainerSpec) error {
+	if len(container.PortBindings) == 0 {
+		return nil
+	}
+
+	containers, err := c.GetContainers()
+	if err != nil {
+		return err
+	}
+
+	var bindings []portBinding
+	for _, existing := range containers {
+		// A recreated container releases its own ports
+		if existing.Name == container.Name && container.Operation == "recreate" {
+			continue
+		}
+		for _, binding := range existing.PortBindings {
+			bindings = append(bindings, newPortBinding(existing.ID, existing.Name, binding.HostIP, binding.Host, binding.Protocol))
+		}
+	}
+
+	var candidates []portBinding
+	for _, binding := range container.PortBindings {
+		candidates = append(candidates, newPortBinding("", container.Name, binding.HostIP, binding.Host, binding.Protocol))
+	}
+
+	conflicts := groupPortConflicts(append(candidates, bindings...), candidates)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	var problems []error
+	for _, conflict := range conflicts {
+		var names []string
+		for _, conflicting := range conflict.Containers {
+			if conflicting.ID != "" {
+				names = append(names, conflicting.Name)
+			}
+		}
+		problems = append(problems, fmt.Errorf("host port %d/%s is already bound by container %s",
+			conflict.HostPort, conflict.Protocol, strings.Join(names, ", ")))
+	}
+	return errors.Join(problems...)
+}
+
+func newPortBinding(containerID string, containerName string, hostIP string, hostPort int32, protocol string) portBinding {
+	protocol = strings.ToLower(protocol)
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	return portBinding{
+		container: PortConflictContainer{
+			ID:     containerID,
+			Name:   containerName,
+			HostIP: hostIP,
+		},
+		hostPort: hostPort,
+		protocol: protocol,
+	}
+}
+
+// groupPortConflicts returns the host ports bound by several containers on overlapping host IPs.
+// When only is not nil, conflicts are limited to those involving one of its bindings
+func groupPortConflicts(bindings []portBinding, only []portBinding) []PortConflict {
+	type portKey struct {
+		hostPort int32
+		protocol string
+	}
+
+	byPort := make(map[portKey][]portBinding)
+	var keys []portKey
+	for _, binding := range bindings {
+		// Host port 0 lets Docker pick a free port
+		if binding.hostPort == 0 {
+			continue
+		}
+		key := portKey{binding.hostPort, binding.protocol}
+		if _, ok := byPort[key]; !ok {
+			keys = append(keys, key)
+		}
+		byPort[key] = append(byPort[key], binding)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].hostPort != keys[j].hostPort {
+			return keys[i].hostPort < keys[j].hostPort
+		}
+		return keys[i].protocol < keys[j].protocol
+	})
+
+	var conflicts []PortConflict
+	for _, key := range keys {
+		samePort := byPort[key]
+		involved := make([]bool, len(samePort))
+		found := false
+		for i := range samePort {
+			for j := i + 1; j < len(samePort); j++ {
+				if samePort[i].sameContainer(samePort[j]) || !hostIPsOverlap(samePort[i].container.HostIP, samePort[j].container.HostIP) {
+					continue
+				}
+				if only != nil && !containsBinding(only, samePort[i]) && !containsBinding(only, samePort[j]) {
+					continue
+				}
+				involved[i], involved[j], found = true, true, true
+			}
+		}
+		if !found {
+			continue
+		}
+
+		conflict := PortConflict{HostPort: key.hostPort, Protocol: key.protocol}
+		for i, binding := range samePort {
+			if involved[i] {
+				conflict.Containers = append(conflict.Containers, binding.container)
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+func (binding portBinding) sameContainer(other portBinding) bool {
+	return binding.container.ID == other.container.ID && binding.container.Name == other.container.Name
+}
+
+func containsBinding(bindings []portBinding, binding portBinding) bool {
+	for _, candidate := range bindings {
+		if candidate == binding {
+			return true
+		}
+	}
+	return false
+}
+
+// hostIPsOverlap reports whether two host IPs can receive on the same address, an unspecified IP listens on all of them
+func hostIPsOverlap(first string, second string) bool {
+	unspecified := func(ip string) bool {
+		return ip == "" || ip == "0.0.0.0" || ip == "::"
+	}
+	return unspecified(first) || unspecified(second) || first == second
+}